@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"github.com/restore/shop/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+type BreakerConfig struct {
+	FailureThreshold int           `yaml:"failure_threshold"`
+	OpenTimeout      time.Duration `yaml:"open_timeout"`
+}
+
+// BreakerStats represents data about the state of a circuit breaker.
+type BreakerStats struct {
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Failures int    `json:"failures"`
+	Opened   uint64 `json:"opened"`
+	Rejected uint64 `json:"rejected"`
+}
+
+// Breaker stops calling an upstream after FailureThreshold consecutive
+// failures, until OpenTimeout has passed and a probe call succeeds.
+type Breaker struct {
+	name        string
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+	opened   uint64
+	rejected uint64
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = map[string]*Breaker{}
+)
+
+func newBreaker(name string, cfg *BreakerConfig) *Breaker {
+	b := &Breaker{
+		name:        name,
+		threshold:   cfg.FailureThreshold,
+		openTimeout: cfg.OpenTimeout,
+		state:       StateClosed,
+	}
+	metrics.SetBreakerOpen(name, false)
+
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	breakers[name] = b
+
+	return b
+}
+
+// Breakers returns the stats of every circuit breaker, sorted by name.
+func Breakers() []BreakerStats {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	result := []BreakerStats{}
+	for _, b := range breakers {
+		result = append(result, b.Stats())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (b *Breaker) Stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return BreakerStats{
+		Name:     b.name,
+		State:    b.state,
+		Failures: b.failures,
+		Opened:   b.opened,
+		Rejected: b.rejected,
+	}
+}
+
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			b.rejected++
+			metrics.BreakerRejected(b.name)
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		if b.probing {
+			b.rejected++
+			metrics.BreakerRejected(b.name)
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !isFailure(err) {
+		if b.state != StateClosed {
+			metrics.SetBreakerOpen(b.name, false)
+		}
+		b.state = StateClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+		b.opened++
+		metrics.SetBreakerOpen(b.name, true)
+	}
+}
+
+// isFailure reports whether err means the upstream is unhealthy, as opposed
+// to an error about the call itself.
+func isFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+func breakerInterceptor(b *Breaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !b.allow() {
+			return status.Errorf(codes.Unavailable, "circuit breaker open for %s", b.name)
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		b.record(err)
+		return err
+	}
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/restore/shop/logger"
+	"github.com/restore/shop/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"os"
+	"time"
+)
+
+// retryable lists the upstream methods retried on UNAVAILABLE. Only reads
+// are listed: an UNAVAILABLE write, like CreatePayment, may have been
+// applied, and calling it again could charge or update twice.
+var retryable = []struct {
+	service string
+	method  string
+}{
+	{"service.user.User", "GetUser"},
+	{"service.product.Product", "GetProduct"},
+}
+
+type Config struct {
+	Address string        `yaml:"address"`
+	Timeout time.Duration `yaml:"timeout"`
+	Retry   RetryConfig   `yaml:"retry"`
+	TLS     TLSConfig     `yaml:"tls"`
+	Breaker BreakerConfig `yaml:"breaker"`
+}
+
+type RetryConfig struct {
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+}
+
+type TLSConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	CAFile     string `yaml:"ca_file"`
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	ServerName string `yaml:"server_name"`
+}
+
+// Dial opens a connection to the upstream gRPC service name.
+func Dial(name string, cfg *Config) (*grpc.ClientConn, error) {
+	creds, err := credentialsFor(&cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	interceptors := []grpc.UnaryClientInterceptor{
+		otelgrpc.UnaryClientInterceptor(),
+		metrics.UnaryClientInterceptor(name),
+		requestIDInterceptor,
+	}
+	if cfg.Breaker.FailureThreshold > 0 {
+		interceptors = append(interceptors, breakerInterceptor(newBreaker(name, &cfg.Breaker)))
+	}
+	interceptors = append(interceptors, timeoutInterceptor(cfg.Timeout))
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(interceptors...),
+	}
+	if cfg.Retry.MaxAttempts > 1 {
+		serviceConfig, err := retryServiceConfig(&cfg.Retry)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithDefaultServiceConfig(serviceConfig))
+	}
+
+	return grpc.Dial(cfg.Address, opts...)
+}
+
+func credentialsFor(cfg *TLSConfig) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsCfg := &tls.Config{
+		ServerName: cfg.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cfg.CAFile != "" {
+		ca, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %s", cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, errors.New("tls cert_file and key_file must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(tlsCfg), nil
+}
+
+// retryServiceConfig builds the gRPC service config retrying the retryable
+// methods of unavailable upstreams.
+func retryServiceConfig(cfg *RetryConfig) (string, error) {
+	initial := cfg.InitialBackoff
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	max := cfg.MaxBackoff
+	if max < initial {
+		max = initial
+	}
+
+	names := []interface{}{}
+	for _, m := range retryable {
+		names = append(names, map[string]interface{}{
+			"service": m.service,
+			"method":  m.method,
+		})
+	}
+
+	serviceConfig := map[string]interface{}{
+		"methodConfig": []interface{}{
+			map[string]interface{}{
+				"name": names,
+				"retryPolicy": map[string]interface{}{
+					"maxAttempts":          cfg.MaxAttempts,
+					"initialBackoff":       fmt.Sprintf("%.3fs", initial.Seconds()),
+					"maxBackoff":           fmt.Sprintf("%.3fs", max.Seconds()),
+					"backoffMultiplier":    2,
+					"retryableStatusCodes": []string{"UNAVAILABLE"},
+				},
+			},
+		},
+	}
+
+	result, err := json.Marshal(serviceConfig)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// timeoutInterceptor bounds every call without a sooner deadline by timeout.
+func timeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if timeout <= 0 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= timeout {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// requestIDInterceptor forwards the request ID of the context to upstreams.
+func requestIDInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if id := logger.RequestID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, logger.RequestIDKey, id)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	paymentpb "github.com/ReStorePUC/protobucket/payment"
+	productpb "github.com/ReStorePUC/protobucket/product"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// unavailableServer fails every call with UNAVAILABLE and counts them.
+type unavailableServer struct {
+	productpb.UnimplementedProductServer
+	paymentpb.UnimplementedPaymentServer
+
+	calls atomic.Int64
+}
+
+func (s *unavailableServer) GetProduct(context.Context, *productpb.GetProductRequest) (*productpb.GetProductResponse, error) {
+	s.calls.Add(1)
+	return nil, status.Error(codes.Unavailable, "unavailable")
+}
+
+func (s *unavailableServer) UnavailableProduct(context.Context, *productpb.UnavailableProductRequest) (*productpb.UnavailableProductResponse, error) {
+	s.calls.Add(1)
+	return nil, status.Error(codes.Unavailable, "unavailable")
+}
+
+func (s *unavailableServer) CreatePayment(context.Context, *paymentpb.CreatePaymentRequest) (*paymentpb.CreatePaymentResponse, error) {
+	s.calls.Add(1)
+	return nil, status.Error(codes.Unavailable, "unavailable")
+}
+
+func TestRetryOnlyReads(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening: %v", err)
+	}
+	srv := grpc.NewServer()
+	upstream := &unavailableServer{}
+	productpb.RegisterProductServer(srv, upstream)
+	paymentpb.RegisterPaymentServer(srv, upstream)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := Dial("test", &Config{
+		Address: lis.Addr().String(),
+		Timeout: 5 * time.Second,
+		Retry: RetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		},
+	})
+	if err != nil {
+		t.Fatalf("error dialing: %v", err)
+	}
+	defer conn.Close()
+
+	product := productpb.NewProductClient(conn)
+	payment := paymentpb.NewPaymentClient(conn)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+		want int64
+	}{
+		{"GetProduct", func() error {
+			_, err := product.GetProduct(ctx, &productpb.GetProductRequest{Id: "1"})
+			return err
+		}, 3},
+		{"UnavailableProduct", func() error {
+			_, err := product.UnavailableProduct(ctx, &productpb.UnavailableProductRequest{Id: "1"})
+			return err
+		}, 1},
+		{"CreatePayment", func() error {
+			_, err := payment.CreatePayment(ctx, &paymentpb.CreatePaymentRequest{})
+			return err
+		}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream.calls.Store(0)
+
+			err := tt.call()
+			if status.Code(err) != codes.Unavailable {
+				t.Fatalf("error = %v, want UNAVAILABLE", err)
+			}
+			if calls := upstream.calls.Load(); calls != tt.want {
+				t.Errorf("calls = %d, want %d", calls, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/restore/shop/client"
+	"github.com/restore/shop/config"
+	"github.com/restore/shop/repository"
+	"github.com/restore/shop/stub"
+)
+
+const (
+	defaultSeedPath = "dev-seed.yaml"
+	devDatabase     = "file::memory:?cache=shared"
+)
+
+// startDev serves the upstreams from stubs seeded from the file at seedPath
+// and keeps the data in an in-memory SQLite database.
+func startDev(seedPath string) (*stub.Server, error) {
+	seed, err := stub.LoadSeed(seedPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stubs, err := stub.Start(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cfg := range []*client.Config{config.NewUserConfig(), config.NewPaymentConfig(), config.NewProductConfig()} {
+		cfg.Address = stubs.Address()
+		cfg.TLS = client.TLSConfig{}
+	}
+	// The in-memory database lives as long as one of its connections, so the
+	// pool must not close them all.
+	dbCfg := config.NewDBConfig()
+	dbCfg.Driver = repository.DriverSQLite
+	dbCfg.Database = devDatabase
+	dbCfg.Replicas = nil
+	dbCfg.Pool = repository.PoolConfig{}
+	return stubs, nil
+}
@@ -1,73 +1,227 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	paymentpb "github.com/ReStorePUC/protobucket/payment"
 	productpb "github.com/ReStorePUC/protobucket/product"
 	pb "github.com/ReStorePUC/protobucket/user"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/restore/shop/client"
 	"github.com/restore/shop/config"
 	"github.com/restore/shop/controller"
 	"github.com/restore/shop/handler"
+	"github.com/restore/shop/logger"
+	"github.com/restore/shop/metrics"
+	shoppb "github.com/restore/shop/proto/shop"
 	"github.com/restore/shop/repository"
+	"github.com/restore/shop/server"
+	"github.com/restore/shop/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"log"
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	config.Init()
+	configPath := flag.String("config", "", "path to the configuration file")
+	dev := flag.Bool("dev", false, "serve stub upstreams and an in-memory database")
+	seedPath := flag.String("seed", defaultSeedPath, "path to the data of the stub upstreams, with -dev")
+	flag.Parse()
+
+	err := config.Init(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *dev {
+		stubs, err := startDev(*seedPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer stubs.Stop()
+	}
 	dbCfg := config.NewDBConfig()
+	srvCfg := config.NewServerConfig()
+
+	appLog, err := logger.New(config.NewLogConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer appLog.Sync()
+
+	shutdownTracing, err := tracing.Init(context.Background(), config.NewTracingConfig())
+	if err != nil {
+		appLog.Fatal("error initializing tracing", zap.Error(err))
+	}
 
-	db, err := repository.Init(dbCfg)
+	db, err := repository.Init(dbCfg, appLog)
+	if err != nil {
+		appLog.Fatal("error connecting to database", zap.Error(err))
+	}
+	sqlDB, err := db.DB()
 	if err != nil {
-		panic(err)
+		appLog.Fatal("error connecting to database", zap.Error(err))
 	}
 
-	conn, err := grpc.Dial("user:50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if flag.Arg(0) == "migrate" {
+		err = migrate(context.Background(), sqlDB, dbCfg.Driver, flag.Args()[1:])
+		sqlDB.Close()
+		if err != nil {
+			appLog.Fatal("error migrating database", zap.Error(err))
+		}
+		return
+	}
+	if *dev {
+		err = migrate(context.Background(), sqlDB, dbCfg.Driver, []string{"up"})
+		if err != nil {
+			appLog.Fatal("error migrating database", zap.Error(err))
+		}
+		appLog.Info("running in development mode with stub upstreams")
+	}
+
+	conn, err := client.Dial("user", config.NewUserConfig())
 	if err != nil {
-		log.Fatalf("did not connect: %v", err)
+		appLog.Fatal("did not connect", zap.Error(err))
 	}
-	defer conn.Close()
 	c := pb.NewUserClient(conn)
 
-	paymentConn, err := grpc.Dial("payment:50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	paymentConn, err := client.Dial("payment", config.NewPaymentConfig())
 	if err != nil {
-		log.Fatalf("did not connect: %v", err)
+		appLog.Fatal("did not connect", zap.Error(err))
 	}
-	defer paymentConn.Close()
 	pc := paymentpb.NewPaymentClient(paymentConn)
 
-	productConn, err := grpc.Dial("product:50053", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	productConn, err := client.Dial("product", config.NewProductConfig())
 	if err != nil {
-		log.Fatalf("did not connect: %v", err)
+		appLog.Fatal("did not connect", zap.Error(err))
 	}
-	defer productConn.Close()
 	prodC := productpb.NewProductClient(productConn)
 
-	sRepo := repository.NewShop(db)
-	sController := controller.NewShop(sRepo, c, prodC, pc)
+	sRepo := repository.NewShop(db, &dbCfg.Retry)
+	sController := controller.NewShop(sRepo, c, prodC, pc, appLog)
 	sHandler := handler.NewShop(sController)
+	sServer := server.NewShop(sController)
+	health := handler.NewHealth(sqlDB, map[string]handler.Connection{
+		"user":    conn,
+		"payment": paymentConn,
+		"product": productConn,
+	})
+
+	lis, err := net.Listen("tcp", srvCfg.GRPCAddress)
+	if err != nil {
+		appLog.Fatal("failed to listen", zap.Error(err))
+	}
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		otelgrpc.UnaryServerInterceptor(),
+		server.RequestID,
+		server.Logger(appLog),
+	))
+	shoppb.RegisterShopServer(grpcServer, sServer)
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			appLog.Fatal("failed to serve", zap.Error(err))
+		}
+	}()
 
-	router := gin.Default()
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(otelgin.Middleware(config.NewTracingConfig().ServiceName))
+	router.Use(handler.RequestID(), handler.Logger(appLog), gin.Recovery())
+	router.Use(handler.MaxBodySize(int64(srvCfg.MaxBodyBytes)))
+	router.Use(metrics.HTTP())
 	router.Use(cors.New(cors.Config{
 		AllowAllOrigins:  true,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
 		AllowHeaders:     []string{"*"},
+		ExposeHeaders:    []string{"X-Total-Count", "X-Next-Cursor", "ETag", "Content-Disposition", logger.RequestIDHeader},
 		AllowCredentials: true,
 		AllowFiles:       true,
 	}))
 
+	router.GET("/healthz", health.Healthz)
+	router.GET("/readyz", health.Readyz)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
 	router.POST("/private/request", sHandler.CreateRequest)
+	router.GET("/private/request/:id", sHandler.GetRequest)
 	router.PUT("/private/request/:id", sHandler.UpdateRequest)
+	router.POST("/private/request/bulk-update", sHandler.BulkUpdateRequests)
+	router.GET("/private/request/search", sHandler.SearchAllRequest)
 	router.GET("/private/request/search/:storeID", sHandler.SearchRequest)
 	router.GET("/private/request/profile/search/:profileID", sHandler.SearchProfileRequest)
+	router.GET("/private/request/export/:storeID", sHandler.ExportRequests)
 	router.POST("/private/confirm-request/:paymentID", sHandler.ConfirmRequest)
 
 	router.POST("/private/payment", sHandler.CreatePayment)
+	router.GET("/private/payment/:id", sHandler.GetPayment)
 	router.PUT("/private/payment/:id", sHandler.UpdatePayment)
 	router.GET("/private/payment/store/:storeID", sHandler.GetPayments)
 	router.GET("/private/payment/search", sHandler.SearchPayments)
+	router.GET("/private/payment/export/:storeID", sHandler.ExportPayments)
+
+	router.GET("/private/report/sales/:storeID", sHandler.SalesReport)
+
+	router.GET("/private/analytics/gmv", sHandler.GMV)
+	router.GET("/private/analytics/top-stores", sHandler.TopStores)
+	router.GET("/private/analytics/top-categories", sHandler.TopCategories)
+	router.GET("/private/analytics/checkouts", sHandler.Checkouts)
+	router.GET("/private/analytics/refunds", sHandler.Refunds)
+
+	srv := &http.Server{
+		Addr:              srvCfg.Address,
+		Handler:           router,
+		ReadTimeout:       srvCfg.ReadTimeout,
+		ReadHeaderTimeout: srvCfg.ReadHeaderTimeout,
+		WriteTimeout:      srvCfg.WriteTimeout,
+		IdleTimeout:       srvCfg.IdleTimeout,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			appLog.Fatal("failed to serve", zap.Error(err))
+		}
+	}()
 
-	router.Run(":8080")
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	stop()
+	appLog.Info("shutting down")
+	health.Drain()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), srvCfg.ShutdownTimeout)
+	defer cancel()
+
+	err = srv.Shutdown(shutdownCtx)
+	if err != nil {
+		appLog.Error("error shutting down http server", zap.Error(err))
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
+
+	for _, c := range []*grpc.ClientConn{conn, paymentConn, productConn} {
+		c.Close()
+	}
+
+	sqlDB.Close()
+
+	err = shutdownTracing(shutdownCtx)
+	if err != nil {
+		appLog.Error("error shutting down tracing", zap.Error(err))
+	}
 }
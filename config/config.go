@@ -1,34 +1,209 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"github.com/restore/shop/client"
+	"github.com/restore/shop/logger"
 	"github.com/restore/shop/repository"
+	"github.com/restore/shop/tracing"
 	"gopkg.in/yaml.v3"
-	"log"
 	"os"
+	"reflect"
+	"strings"
+	"time"
 )
 
 const EmailHeader = "X-Consumer-Username"
 
 type Configuration struct {
-	Mysql repository.Config `yaml:"mysql"`
+	Server    Server            `yaml:"server"`
+	Log       logger.Config     `yaml:"log"`
+	Tracing   tracing.Config    `yaml:"tracing"`
+	Database  repository.Config `yaml:"database"`
+	Upstreams Upstreams         `yaml:"upstreams"`
 }
 
+type Server struct {
+	Address           string        `yaml:"address"`
+	GRPCAddress       string        `yaml:"grpc_address"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+	MaxBodyBytes      int           `yaml:"max_body_bytes"`
+}
+
+type Upstreams struct {
+	User    client.Config `yaml:"user"`
+	Payment client.Config `yaml:"payment"`
+	Product client.Config `yaml:"product"`
+}
+
+const (
+	DefaultPath = "config.yaml"
+	envPrefix   = "SHOP"
+
+	// legacyDatabaseKey is the former name of the database section, and of
+	// the SHOP_MYSQL_* variables, still read under the new ones.
+	legacyDatabaseKey = "mysql"
+)
+
 var config Configuration
 
-func Init() {
-	f, err := os.Open("config.yaml")
+// Init loads the configuration, layering the defaults, the file at path and
+// the SHOP_* environment variables. An empty path loads DefaultPath when it
+// exists.
+func Init(path string) error {
+	config = defaults()
+
+	if path == "" {
+		path = DefaultPath
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			path = ""
+		}
+	}
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		err = decode(content, &config)
+		if err != nil {
+			return fmt.Errorf("error decoding %s: %w", path, err)
+		}
+	}
+
+	err := loadEnvValue(envPrefix+"_"+strings.ToUpper(legacyDatabaseKey), reflect.ValueOf(&config.Database).Elem())
+	if err != nil {
+		return err
+	}
+	err = loadEnv(envPrefix, &config)
+	if err != nil {
+		return err
+	}
+
+	return validate(&config)
+}
+
+// decode decodes the yaml content into cfg, reading the legacy database
+// section when the database one is missing.
+func decode(content []byte, cfg *Configuration) error {
+	err := yaml.Unmarshal(content, cfg)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer f.Close()
 
-	decoder := yaml.NewDecoder(f)
-	err = decoder.Decode(&config)
+	var sections map[string]yaml.Node
+	err = yaml.Unmarshal(content, &sections)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	legacy, ok := sections[legacyDatabaseKey]
+	if !ok {
+		return nil
+	}
+	if _, ok := sections["database"]; ok {
+		return fmt.Errorf("both database and %s are set; %s is the former name of database", legacyDatabaseKey, legacyDatabaseKey)
+	}
+	return legacy.Decode(&cfg.Database)
+}
+
+func defaults() Configuration {
+	upstream := func(address string) client.Config {
+		return client.Config{
+			Address: address,
+			Timeout: 2 * time.Second,
+			Retry: client.RetryConfig{
+				MaxAttempts:    3,
+				InitialBackoff: 100 * time.Millisecond,
+				MaxBackoff:     time.Second,
+			},
+			Breaker: client.BreakerConfig{
+				FailureThreshold: 5,
+				OpenTimeout:      10 * time.Second,
+			},
+		}
+	}
+
+	return Configuration{
+		Server: Server{
+			Address:           ":8080",
+			GRPCAddress:       ":50054",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       time.Minute,
+			ShutdownTimeout:   20 * time.Second,
+			MaxBodyBytes:      1 << 20,
+		},
+		Log: logger.Config{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: tracing.Config{
+			Exporter:    tracing.ExporterNone,
+			SampleRatio: 1,
+			ServiceName: "shop",
+		},
+		Database: repository.Config{
+			Driver:   repository.DriverMySQL,
+			Host:     "db",
+			Port:     "3306",
+			User:     "root",
+			Database: "shopdb",
+			Pool: repository.PoolConfig{
+				MaxOpenConns:    25,
+				MaxIdleConns:    10,
+				ConnMaxLifetime: 30 * time.Minute,
+				ConnMaxIdleTime: 5 * time.Minute,
+			},
+			ConnectRetry: repository.RetryConfig{
+				MaxAttempts:    10,
+				InitialBackoff: 500 * time.Millisecond,
+				MaxBackoff:     10 * time.Second,
+			},
+			Retry: repository.RetryConfig{
+				MaxAttempts:    3,
+				InitialBackoff: 50 * time.Millisecond,
+				MaxBackoff:     time.Second,
+			},
+		},
+		Upstreams: Upstreams{
+			User:    upstream("user:50051"),
+			Payment: upstream("payment:50051"),
+			Product: upstream("product:50053"),
+		},
 	}
 }
 
+func NewServerConfig() *Server {
+	return &config.Server
+}
+
+func NewLogConfig() *logger.Config {
+	return &config.Log
+}
+
+func NewTracingConfig() *tracing.Config {
+	return &config.Tracing
+}
+
 func NewDBConfig() *repository.Config {
-	return &config.Mysql
+	return &config.Database
+}
+
+func NewUserConfig() *client.Config {
+	return &config.Upstreams.User
+}
+
+func NewPaymentConfig() *client.Config {
+	return &config.Upstreams.Payment
+}
+
+func NewProductConfig() *client.Config {
+	return &config.Upstreams.Product
 }
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatalf("error writing config: %v", err)
+	}
+	return path
+}
+
+func TestLegacyDatabaseSection(t *testing.T) {
+	path := writeConfig(t, `
+mysql:
+  host: legacy-db
+  port: 3307
+  user: shop
+  password: secret
+  database: legacydb
+`)
+
+	err := Init(path)
+	if err != nil {
+		t.Fatalf("error loading config: %v", err)
+	}
+
+	db := NewDBConfig()
+	if db.Host != "legacy-db" || db.Port != "3307" || db.User != "shop" || db.Password != "secret" || db.Database != "legacydb" {
+		t.Errorf("database = %+v, want the mysql section", db)
+	}
+	if db.Driver != "mysql" || db.Pool.MaxOpenConns != 25 {
+		t.Errorf("database = %+v, want the defaults of the fields not set", db)
+	}
+}
+
+func TestLegacyAndDatabaseSections(t *testing.T) {
+	path := writeConfig(t, `
+mysql:
+  host: legacy-db
+database:
+  host: db
+`)
+
+	err := Init(path)
+	if err == nil {
+		t.Fatal("expected an error with both sections set")
+	}
+}
+
+func TestLegacyDatabaseEnv(t *testing.T) {
+	path := writeConfig(t, "")
+	t.Setenv("SHOP_MYSQL_HOST", "legacy-db")
+	t.Setenv("SHOP_MYSQL_PASSWORD", "secret")
+	t.Setenv("SHOP_DATABASE_PASSWORD", "new-secret")
+
+	err := Init(path)
+	if err != nil {
+		t.Fatalf("error loading config: %v", err)
+	}
+
+	db := NewDBConfig()
+	if db.Host != "legacy-db" {
+		t.Errorf("host = %q, want SHOP_MYSQL_HOST", db.Host)
+	}
+	if db.Password != "new-secret" {
+		t.Errorf("password = %q, want SHOP_DATABASE_PASSWORD over SHOP_MYSQL_PASSWORD", db.Password)
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// loadEnv overrides the fields of cfg from the environment. The variable of a
+// field is prefix followed by its yaml path, e.g. SHOP_DATABASE_HOST. The same
+// variable suffixed by _FILE reads the value from a file, for secrets. Lists
+// are comma separated.
+func loadEnv(prefix string, cfg interface{}) error {
+	return loadEnvValue(prefix, reflect.ValueOf(cfg).Elem())
+}
+
+func loadEnvValue(name string, v reflect.Value) error {
+	if v.Kind() == reflect.Struct {
+		for i := 0; i < v.NumField(); i++ {
+			tag := strings.Split(v.Type().Field(i).Tag.Get("yaml"), ",")[0]
+			if tag == "" || tag == "-" {
+				continue
+			}
+			err := loadEnvValue(name+"_"+strings.ToUpper(tag), v.Field(i))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	value, ok, err := lookupEnv(name)
+	if err != nil || !ok {
+		return err
+	}
+
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		values := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+		v.Set(reflect.ValueOf(values))
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		v.SetInt(int64(n))
+	default:
+		return fmt.Errorf("%s can't be set from the environment", name)
+	}
+	return nil
+}
+
+func lookupEnv(name string) (string, bool, error) {
+	if path, ok := os.LookupEnv(name + "_FILE"); ok {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("error reading %s_FILE: %w", name, err)
+		}
+		return strings.TrimSpace(string(content)), true, nil
+	}
+
+	value, ok := os.LookupEnv(name)
+	return value, ok, nil
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"github.com/restore/shop/client"
+	"github.com/restore/shop/repository"
+	"github.com/restore/shop/tracing"
+	"go.uber.org/zap/zapcore"
+	"strconv"
+	"strings"
+)
+
+// ValidationError lists every missing or invalid field of a configuration.
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e, "\n  - ")
+}
+
+func validate(cfg *Configuration) error {
+	var errs ValidationError
+
+	required := func(field, value string) {
+		if value == "" {
+			errs = append(errs, field+" is required")
+		}
+	}
+
+	required("server.address", cfg.Server.Address)
+	required("server.grpc_address", cfg.Server.GRPCAddress)
+	if cfg.Server.ReadTimeout < 0 || cfg.Server.ReadHeaderTimeout < 0 || cfg.Server.WriteTimeout < 0 ||
+		cfg.Server.IdleTimeout < 0 || cfg.Server.ShutdownTimeout < 0 {
+		errs = append(errs, "server timeouts must not be negative")
+	}
+	if cfg.Server.MaxBodyBytes <= 0 {
+		errs = append(errs, "server.max_body_bytes must be positive")
+	}
+
+	if _, err := zapcore.ParseLevel(cfg.Log.Level); err != nil {
+		errs = append(errs, "log.level must be one of debug, info, warn, error")
+	}
+	if cfg.Log.Format != "json" && cfg.Log.Format != "console" {
+		errs = append(errs, "log.format must be json or console")
+	}
+
+	switch cfg.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout:
+	case tracing.ExporterOTLP:
+		required("tracing.endpoint", cfg.Tracing.Endpoint)
+	default:
+		errs = append(errs, "tracing.exporter must be one of none, stdout, otlp")
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		errs = append(errs, "tracing.sample_ratio must be between 0 and 1")
+	}
+	required("tracing.service_name", cfg.Tracing.ServiceName)
+
+	switch cfg.Database.Driver {
+	case repository.DriverMySQL, repository.DriverPostgres:
+		required("database.host", cfg.Database.Host)
+		required("database.user", cfg.Database.User)
+		if _, err := strconv.Atoi(cfg.Database.Port); err != nil {
+			errs = append(errs, "database.port must be a number")
+		}
+	case repository.DriverSQLite:
+		if len(cfg.Database.Replicas) > 0 {
+			errs = append(errs, "database.replicas are not supported by sqlite")
+		}
+	default:
+		errs = append(errs, "database.driver must be one of mysql, postgres, sqlite")
+	}
+	required("database.database", cfg.Database.Database)
+	if cfg.Database.Pool.MaxOpenConns < 0 || cfg.Database.Pool.MaxIdleConns < 0 ||
+		cfg.Database.Pool.ConnMaxLifetime < 0 || cfg.Database.Pool.ConnMaxIdleTime < 0 {
+		errs = append(errs, "database.pool values must not be negative")
+	}
+	if cfg.Database.Pool.MaxOpenConns > 0 && cfg.Database.Pool.MaxIdleConns > cfg.Database.Pool.MaxOpenConns {
+		errs = append(errs, "database.pool.max_idle_conns must not exceed max_open_conns")
+	}
+	dbRetry := func(field string, c *repository.RetryConfig) {
+		if c.MaxAttempts < 0 || c.InitialBackoff < 0 || c.MaxBackoff < 0 {
+			errs = append(errs, field+" values must not be negative")
+		}
+	}
+	dbRetry("database.connect_retry", &cfg.Database.ConnectRetry)
+	dbRetry("database.retry", &cfg.Database.Retry)
+
+	upstream := func(field string, c *client.Config) {
+		required(field+".address", c.Address)
+		if c.Timeout < 0 {
+			errs = append(errs, field+".timeout must not be negative")
+		}
+		if c.Retry.MaxAttempts < 0 || c.Retry.MaxAttempts > 5 {
+			errs = append(errs, field+".retry.max_attempts must be between 0 and 5")
+		}
+		if c.Retry.InitialBackoff < 0 || c.Retry.MaxBackoff < 0 {
+			errs = append(errs, field+".retry backoffs must not be negative")
+		}
+		if c.Breaker.FailureThreshold < 0 || c.Breaker.OpenTimeout < 0 {
+			errs = append(errs, field+".breaker values must not be negative")
+		}
+		if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+			errs = append(errs, field+".tls.cert_file and key_file must be set together")
+		}
+		if !c.TLS.Enabled && (c.TLS.CAFile != "" || c.TLS.CertFile != "") {
+			errs = append(errs, field+".tls certificates are set but tls is not enabled")
+		}
+	}
+
+	upstream("upstreams.user", &cfg.Upstreams.User)
+	upstream("upstreams.payment", &cfg.Upstreams.Payment)
+	upstream("upstreams.product", &cfg.Upstreams.Product)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/logger"
+	"go.uber.org/zap"
+)
+
+// GMV aggregates the sales of every store per period, a month by default.
+func (s *Shop) GMV(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.GMVPeriod, error) {
+	log := logger.For(ctx, s.log)
+
+	filter, err := s.analyticsFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.repo.GMV(ctx, filter)
+	if err != nil {
+		log.Error(
+			"error aggregating gmv",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return result, nil
+}
+
+// TopStores ranks the stores by their sales.
+func (s *Shop) TopStores(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.StoreSales, error) {
+	log := logger.For(ctx, s.log)
+
+	filter, err := s.analyticsFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.repo.TopStores(ctx, filter)
+	if err != nil {
+		log.Error(
+			"error ranking stores",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return result, nil
+}
+
+// TopCategories ranks the product categories by their sales.
+func (s *Shop) TopCategories(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.CategorySales, error) {
+	log := logger.For(ctx, s.log)
+
+	filter, err := s.analyticsFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.repo.TopCategories(ctx, filter)
+	if err != nil {
+		log.Error(
+			"error ranking categories",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return result, nil
+}
+
+// Checkouts reports the conversion and abandonment of checkouts.
+func (s *Shop) Checkouts(ctx context.Context, filter entity.AnalyticsFilter) (*entity.CheckoutStats, error) {
+	log := logger.For(ctx, s.log)
+
+	filter, err := s.analyticsFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.repo.Checkouts(ctx, filter)
+	if err != nil {
+		log.Error(
+			"error counting checkouts",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return result, nil
+}
+
+// Refunds reports the refund rate of paid Requests.
+func (s *Shop) Refunds(ctx context.Context, filter entity.AnalyticsFilter) (*entity.RefundStats, error) {
+	log := logger.For(ctx, s.log)
+
+	filter, err := s.analyticsFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.repo.Refunds(ctx, filter)
+	if err != nil {
+		log.Error(
+			"error counting refunds",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return result, nil
+}
+
+// analyticsFilter checks that the caller is an admin and returns filter with
+// its defaults, once valid.
+func (s *Shop) analyticsFilter(ctx context.Context, filter entity.AnalyticsFilter) (entity.AnalyticsFilter, error) {
+	log := logger.For(ctx, s.log)
+
+	err := s.checkAdmin(ctx)
+	if err != nil {
+		return filter, err
+	}
+
+	if filter.Period == "" {
+		filter.Period = entity.PeriodMonth
+	}
+	if filter.Limit == 0 {
+		filter.Limit = entity.DefaultTopLimit
+	}
+	if filter.AbandonedAfter == 0 {
+		filter.AbandonedAfter = entity.DefaultAbandonedAfter
+	}
+
+	err = validateAnalyticsFilter(filter)
+	if err != nil {
+		log.Error(
+			"error validating filter",
+			zap.Error(err),
+		)
+		return filter, err
+	}
+	return filter, nil
+}
+
+func validateAnalyticsFilter(filter entity.AnalyticsFilter) error {
+	switch filter.Period {
+	case entity.PeriodDay, entity.PeriodWeek, entity.PeriodMonth:
+	default:
+		return errors.New("invalid period")
+	}
+	if filter.Limit < 0 || filter.Limit > entity.MaxLimit {
+		return errors.New("invalid limit")
+	}
+	if filter.AbandonedAfter < 0 {
+		return errors.New("invalid abandoned after")
+	}
+	if !filter.InitialDate.IsZero() && !filter.EndDate.IsZero() && filter.InitialDate.After(filter.EndDate) {
+		return errors.New("initial date after end date")
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/restore/shop/config"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/logger"
+	"go.uber.org/zap"
+)
+
+// BulkUpdateRequests updates the status and track of many Requests. Every
+// update is validated first. When atomic, either every update is applied in
+// a single transaction or none is; otherwise each valid update is applied on
+// its own. It returns the result of each update, in order.
+func (s *Shop) BulkUpdateRequests(ctx context.Context, updates []entity.StatusUpdate, atomic bool) ([]entity.StatusUpdateResult, error) {
+	log := logger.For(ctx, s.log)
+
+	admin := ctx.Value(config.EmailHeader)
+	user, err := s.getUser(ctx, admin.(string))
+	if err != nil {
+		log.Error(
+			"error getting admin",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	if !user.IsAdmin {
+		log.Error(
+			"unauthorized action",
+		)
+		return nil, entity.ErrUnauthorized
+	}
+
+	if len(updates) == 0 || len(updates) > entity.MaxBulkUpdates {
+		return nil, fmt.Errorf("updates must have between 1 and %d items", entity.MaxBulkUpdates)
+	}
+
+	ids := []int{}
+	for _, update := range updates {
+		ids = append(ids, update.ID)
+	}
+	current, err := s.repo.GetRequests(ctx, ids)
+	if err != nil {
+		log.Error(
+			"error to get requests",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	byID := map[int]entity.Request{}
+	for _, req := range current {
+		byID[req.ID] = req
+	}
+
+	results := make([]entity.StatusUpdateResult, len(updates))
+	valid := []entity.Request{}
+	positions := []int{}
+	seen := map[int]bool{}
+	for i, update := range updates {
+		results[i].ID = update.ID
+
+		req, ok := byID[update.ID]
+		switch {
+		case seen[update.ID]:
+			err = errors.New("duplicated request")
+		case !ok:
+			err = entity.ErrRequestNotFound
+		default:
+			err = checkStatusUpdate(req, update.Version, update.Status)
+		}
+		seen[update.ID] = true
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		version := update.Version
+		if version == 0 {
+			version = req.Version
+		}
+		valid = append(valid, entity.Request{
+			ID:      update.ID,
+			Status:  update.Status,
+			Track:   update.Track,
+			Version: version,
+		})
+		positions = append(positions, i)
+	}
+
+	if atomic {
+		if len(valid) != len(updates) {
+			return results, entity.ErrBulkRejected
+		}
+
+		err = s.repo.UpdateRequests(ctx, valid)
+		if err != nil {
+			log.Error(
+				"error to update requests",
+				zap.Error(err),
+			)
+			for i := range results {
+				results[i].Error = err.Error()
+			}
+			return results, err
+		}
+
+		for j, req := range valid {
+			results[positions[j]].Applied = true
+			results[positions[j]].Version = req.Version
+		}
+		return results, nil
+	}
+
+	for j := range valid {
+		req := valid[j]
+		err = s.repo.UpdateRequest(ctx, req.ID, &req)
+		if err != nil {
+			log.Warn(
+				"error to update request",
+				zap.Int("id", req.ID),
+				zap.Error(err),
+			)
+			results[positions[j]].Error = err.Error()
+			continue
+		}
+		results[positions[j]].Applied = true
+		results[positions[j]].Version = req.Version
+	}
+	return results, nil
+}
+
+// checkStatusUpdate validates an update of current to status, expecting
+// current to be at version. A zero version matches any version.
+func checkStatusUpdate(current entity.Request, version int, status string) error {
+	if version != 0 && version != current.Version {
+		return entity.ErrVersionConflict
+	}
+	return entity.ValidateTransition(current.Status, status)
+}
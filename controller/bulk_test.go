@@ -0,0 +1,67 @@
+package controller_test
+
+import (
+	"context"
+	"errors"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/fixture"
+	"testing"
+)
+
+func TestBulkUpdateRequests(t *testing.T) {
+	updates := []entity.StatusUpdate{
+		{ID: 1, Status: "shipped", Track: "BR123"},
+		{ID: 2, Status: "created"},
+		{ID: 3, Status: ""},
+		{ID: 4, Status: "shipped"},
+	}
+
+	tests := []struct {
+		name    string
+		atomic  bool
+		err     error
+		applied []bool
+	}{
+		{"per item", false, nil, []bool{true, false, false, false}},
+		{"atomic", true, entity.ErrBulkRejected, []bool{false, false, false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shop := fixture.NewShop()
+			for i := 0; i < 3; i++ {
+				req := fixture.Request(1, 10+i, 2, "preparing")
+				err := shop.Repository.CreateRequest(context.Background(), &req)
+				if err != nil {
+					t.Fatalf("error creating request: %v", err)
+				}
+			}
+
+			results, err := shop.BulkUpdateRequests(fixture.Context(fixture.AdminEmail), updates, tt.atomic)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if len(results) != len(updates) {
+				t.Fatalf("results = %d, want %d", len(results), len(updates))
+			}
+			for i, result := range results {
+				if result.Applied != tt.applied[i] {
+					t.Errorf("result %d = %+v, want applied %t", result.ID, result, tt.applied[i])
+				}
+			}
+			// Back to created, without a status and missing.
+			for _, result := range results[1:] {
+				if result.Error == "" {
+					t.Errorf("result %d has no error", result.ID)
+				}
+			}
+
+			want := "shipped"
+			if tt.atomic {
+				want = "preparing"
+			}
+			if status := shop.Repository.Requests()[0].Status; status != want {
+				t.Errorf("status = %q, want %q", status, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+type cacheItem[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// cache keeps values for a short time.
+type cache[K comparable, V any] struct {
+	ttl   time.Duration
+	mu    sync.RWMutex
+	items map[K]cacheItem[V]
+}
+
+func newCache[K comparable, V any](ttl time.Duration) *cache[K, V] {
+	return &cache[K, V]{
+		ttl:   ttl,
+		items: map[K]cacheItem[V]{},
+	}
+}
+
+func (c *cache[K, V]) get(key K) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, ok := c.items[key]
+	if !ok || time.Now().After(item.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return item.value, true
+}
+
+func (c *cache[K, V]) set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, item := range c.items {
+		if now.After(item.expiresAt) {
+			delete(c.items, k)
+		}
+	}
+	c.items[key] = cacheItem[V]{
+		value:     value,
+		expiresAt: now.Add(c.ttl),
+	}
+}
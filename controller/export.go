@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"context"
+	"github.com/restore/shop/config"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/logger"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// ExportRequests calls write with the Requests of a store matching filter,
+// enriched, one page at a time, so exports don't hold every Request.
+func (s *Shop) ExportRequests(ctx context.Context, storeID string, filter entity.RequestFilter, write func([]entity.Request) error) error {
+	log := logger.For(ctx, s.log)
+
+	id, err := s.adminStore(ctx, storeID)
+	if err != nil {
+		return err
+	}
+	filter.StoreID = id
+
+	err = validateRequestFilter(filter)
+	if err != nil {
+		log.Error(
+			"error validating filter",
+			zap.Error(err),
+		)
+		return err
+	}
+
+	page := entity.Page{Limit: entity.MaxLimit, Sort: entity.SortAsc}
+	for {
+		result, next, err := s.repo.ListRequests(ctx, filter, page)
+		if err != nil {
+			log.Error(
+				"error to list requests",
+				zap.Error(err),
+			)
+			return err
+		}
+
+		s.enrichRequests(ctx, result, filter.Refresh)
+
+		err = write(result)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		page.Cursor = next
+	}
+}
+
+// ExportPayments calls write with the Payments of a store matching filter,
+// enriched, one page at a time, so exports don't hold every Payment.
+func (s *Shop) ExportPayments(ctx context.Context, storeID string, filter entity.PaymentFilter, write func([]entity.Payment) error) error {
+	log := logger.For(ctx, s.log)
+
+	id, err := s.adminStore(ctx, storeID)
+	if err != nil {
+		return err
+	}
+	filter.StoreID = id
+
+	err = validatePaymentFilter(filter)
+	if err != nil {
+		log.Error(
+			"error validating filter",
+			zap.Error(err),
+		)
+		return err
+	}
+
+	page := entity.Page{Limit: entity.MaxLimit, Sort: entity.SortAsc}
+	for {
+		result, next, err := s.repo.ListPayments(ctx, filter, page)
+		if err != nil {
+			log.Error(
+				"error to list payments",
+				zap.Error(err),
+			)
+			return err
+		}
+
+		s.enrichPayments(ctx, result)
+
+		err = write(result)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		page.Cursor = next
+	}
+}
+
+// adminStore checks that the caller is an admin, who may export and report
+// on the data of storeID, and returns its ID.
+func (s *Shop) adminStore(ctx context.Context, storeID string) (int, error) {
+	log := logger.For(ctx, s.log)
+
+	err := s.checkAdmin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := strconv.Atoi(storeID)
+	if err != nil {
+		log.Error(
+			"error validating id",
+			zap.Error(err),
+		)
+		return 0, err
+	}
+	return id, nil
+}
+
+// checkAdmin checks that the caller is an admin.
+func (s *Shop) checkAdmin(ctx context.Context) error {
+	log := logger.For(ctx, s.log)
+
+	admin := ctx.Value(config.EmailHeader)
+	user, err := s.getUser(ctx, admin.(string))
+	if err != nil {
+		log.Error(
+			"error getting admin",
+			zap.Error(err),
+		)
+		return err
+	}
+	if !user.IsAdmin {
+		log.Error(
+			"unauthorized action",
+		)
+		return entity.ErrUnauthorized
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package controller_test
+
+import (
+	"context"
+	"errors"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/fixture"
+	"testing"
+)
+
+func TestExportRequests(t *testing.T) {
+	ctx := context.Background()
+	shop := fixture.NewShop()
+	total := 2*entity.MaxLimit + 1
+	for i := 0; i < total; i++ {
+		req := fixture.Request(1, 10, 2, "preparing")
+		err := shop.Repository.CreateRequest(ctx, &req)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+	}
+
+	pages, rows := 0, 0
+	err := shop.ExportRequests(fixture.Context(fixture.AdminEmail), "1", entity.RequestFilter{}, func(requests []entity.Request) error {
+		pages++
+		rows += len(requests)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error exporting requests: %v", err)
+	}
+	if pages != 3 || rows != total {
+		t.Errorf("exported %d rows in %d pages, want %d in 3", rows, pages, total)
+	}
+
+	err = shop.ExportRequests(fixture.Context(fixture.UserEmail), "1", entity.RequestFilter{}, func([]entity.Request) error {
+		t.Error("export written for a non admin")
+		return nil
+	})
+	if !errors.Is(err, entity.ErrUnauthorized) {
+		t.Errorf("error = %v, want %v", err, entity.ErrUnauthorized)
+	}
+}
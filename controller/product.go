@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	productpb "github.com/ReStorePUC/protobucket/product"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	enrichWorkers   = 8
+	productCacheTTL = time.Minute
+
+	productNotFound    = "product not found"
+	productUnavailable = "product temporarily unavailable"
+)
+
+// enrichRequests fills the Product of each request, from the snapshot taken
+// at checkout or, when missing or refresh is set, from the product service.
+// Requests whose product can't be fetched are kept with a nil Product and a
+// Warning.
+func (s *Shop) enrichRequests(ctx context.Context, requests []entity.Request, refresh bool) {
+	ids := []int{}
+	for i, req := range requests {
+		if !refresh && req.Snapshot.Name != "" {
+			requests[i].Product = snapshotProduct(req)
+			continue
+		}
+		ids = append(ids, req.ProductID)
+	}
+
+	products, warnings := s.fetchProducts(ctx, ids)
+	for i, req := range requests {
+		if requests[i].Product != nil {
+			continue
+		}
+		requests[i].Product = products[req.ProductID]
+		requests[i].Warning = warnings[req.ProductID]
+	}
+}
+
+// enrichPayments fills the Product of each payment from the product service.
+func (s *Shop) enrichPayments(ctx context.Context, payments []entity.Payment) {
+	ids := []int{}
+	for _, payment := range payments {
+		ids = append(ids, payment.ProductID)
+	}
+
+	products, _ := s.fetchProducts(ctx, ids)
+	for i, payment := range payments {
+		payments[i].Product = products[payment.ProductID]
+	}
+}
+
+// fetchProducts gets the products of ids, from the cache or the product
+// service. Each product is fetched once, with bounded parallelism. Products
+// that can't be fetched are missing from the result and have a warning.
+func (s *Shop) fetchProducts(ctx context.Context, ids []int) (map[int]*entity.Product, map[int]string) {
+	log := logger.For(ctx, s.log)
+
+	products := map[int]*entity.Product{}
+	warnings := map[int]string{}
+	missing := []int{}
+	for _, id := range ids {
+		if _, ok := products[id]; ok {
+			continue
+		}
+		prod, ok := s.products.get(id)
+		if !ok {
+			missing = append(missing, id)
+		}
+		products[id] = prod
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, enrichWorkers)
+	)
+	for _, id := range missing {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			prod, err := s.getProduct(ctx, id)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Warn(
+					"error to get product",
+					zap.Int("product_id", id),
+					zap.Error(err),
+				)
+				warnings[id] = productUnavailable
+				if status.Code(err) == codes.NotFound {
+					warnings[id] = productNotFound
+				}
+				return
+			}
+			products[id] = prod
+			s.products.set(id, prod)
+		}(id)
+	}
+	wg.Wait()
+
+	return products, warnings
+}
+
+func (s *Shop) getProduct(ctx context.Context, id int) (*entity.Product, error) {
+	prod, err := s.product.GetProduct(ctx, &productpb.GetProductRequest{Id: strconv.Itoa(id)})
+	if err != nil {
+		return nil, err
+	}
+
+	imgs := []entity.Image{}
+	for _, img := range prod.Images {
+		imgs = append(imgs, entity.Image{
+			ID:        int(img.Id),
+			ImagePath: img.ImagePath,
+			ProductID: int(img.ProductId),
+		})
+	}
+	return &entity.Product{
+		ID:          int(prod.Id),
+		Name:        prod.Name,
+		Description: prod.Description,
+		Categories:  prod.Categories,
+		Size:        prod.Size,
+		Price:       float64(prod.Price),
+		Tax:         float64(prod.Tax),
+		Available:   prod.Available,
+		StoreID:     int(prod.StoreId),
+		Images:      imgs,
+	}, nil
+}
+
+// newSnapshot keeps the data of product shown to the buyer at checkout.
+func newSnapshot(product *entity.Product) entity.ProductSnapshot {
+	return entity.ProductSnapshot{
+		Name:        product.Name,
+		Description: product.Description,
+		Categories:  product.Categories,
+		Size:        product.Size,
+		Price:       product.Price,
+		Tax:         product.Tax,
+		Available:   product.Available,
+		Images:      product.Images,
+	}
+}
+
+func snapshotProduct(request entity.Request) *entity.Product {
+	return &entity.Product{
+		ID:          request.ProductID,
+		Name:        request.Snapshot.Name,
+		Description: request.Snapshot.Description,
+		Categories:  request.Snapshot.Categories,
+		Size:        request.Snapshot.Size,
+		Price:       request.Snapshot.Price,
+		Tax:         request.Snapshot.Tax,
+		Available:   request.Snapshot.Available,
+		StoreID:     request.StoreID,
+		Images:      request.Snapshot.Images,
+	}
+}
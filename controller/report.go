@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/logger"
+	"go.uber.org/zap"
+)
+
+// SalesReport aggregates the sales of a store per period, a month by default.
+func (s *Shop) SalesReport(ctx context.Context, storeID string, filter entity.ReportFilter) (*entity.SalesReport, error) {
+	log := logger.For(ctx, s.log)
+
+	id, err := s.adminStore(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	filter.StoreID = id
+
+	if filter.Period == "" {
+		filter.Period = entity.PeriodMonth
+	}
+	err = validateReportFilter(filter)
+	if err != nil {
+		log.Error(
+			"error validating filter",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	result, err := s.repo.SalesReport(ctx, filter)
+	if err != nil {
+		log.Error(
+			"error building sales report",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return result, nil
+}
+
+func validateReportFilter(filter entity.ReportFilter) error {
+	switch filter.Period {
+	case entity.PeriodDay, entity.PeriodWeek, entity.PeriodMonth:
+	default:
+		return errors.New("invalid period")
+	}
+	if !filter.InitialDate.IsZero() && !filter.EndDate.IsZero() && filter.InitialDate.After(filter.EndDate) {
+		return errors.New("initial date after end date")
+	}
+	return nil
+}
@@ -3,48 +3,92 @@ package controller
 import (
 	"context"
 	"errors"
+	"fmt"
 	paymentpb "github.com/ReStorePUC/protobucket/payment"
 	productpb "github.com/ReStorePUC/protobucket/product"
 	pb "github.com/ReStorePUC/protobucket/user"
 	"github.com/restore/shop/config"
 	"github.com/restore/shop/entity"
+	"github.com/restore/shop/logger"
+	"github.com/restore/shop/metrics"
 	"go.uber.org/zap"
+	"slices"
 	"strconv"
-	"time"
 )
 
 type repository interface {
 	CreateRequest(ctx context.Context, request *entity.Request) error
 	UpdateRequest(ctx context.Context, id int, request *entity.Request) error
 	ConfirmRequests(ctx context.Context, paymentID string) error
+	UpdateRequests(ctx context.Context, requests []entity.Request) error
 	GetRequestByPayment(ctx context.Context, paymentID string) ([]entity.Request, error)
-	SearchRequest(ctx context.Context, id int, status string, init, end time.Time) ([]entity.Request, error)
-	SearchProfileRequest(ctx context.Context, id int, status string, init, end time.Time) ([]entity.Request, error)
+	GetRequest(ctx context.Context, id int) (*entity.Request, error)
+	GetRequests(ctx context.Context, ids []int) ([]entity.Request, error)
+	SearchRequest(ctx context.Context, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error)
+	ListRequests(ctx context.Context, filter entity.RequestFilter, page entity.Page) ([]entity.Request, string, error)
 
 	CreatePayment(ctx context.Context, payment *entity.Payment) (int, error)
 	UpdatePayment(ctx context.Context, id int, payment *entity.Payment) error
-	GetPayments(ctx context.Context, id int) ([]entity.Payment, error)
-	SearchPayment(ctx context.Context, status string, init, end time.Time) ([]entity.Payment, error)
+	GetPayment(ctx context.Context, id int) (*entity.Payment, error)
+	GetPayments(ctx context.Context, id int, page entity.Page) ([]entity.Payment, entity.PageInfo, error)
+	SearchPayment(ctx context.Context, filter entity.PaymentFilter, page entity.Page) ([]entity.Payment, entity.PageInfo, error)
+	ListPayments(ctx context.Context, filter entity.PaymentFilter, page entity.Page) ([]entity.Payment, string, error)
+
+	SalesReport(ctx context.Context, filter entity.ReportFilter) (*entity.SalesReport, error)
+	GMV(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.GMVPeriod, error)
+	TopStores(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.StoreSales, error)
+	TopCategories(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.CategorySales, error)
+	Checkouts(ctx context.Context, filter entity.AnalyticsFilter) (*entity.CheckoutStats, error)
+	Refunds(ctx context.Context, filter entity.AnalyticsFilter) (*entity.RefundStats, error)
 }
 
 type Shop struct {
-	repo    repository
-	service pb.UserClient
-	product productpb.ProductClient
-	payment paymentpb.PaymentClient
+	repo       repository
+	service    pb.UserClient
+	product    productpb.ProductClient
+	payment    paymentpb.PaymentClient
+	log        *zap.Logger
+	products   *cache[int, *entity.Product]
+	principals *cache[string, *pb.GetUserResponse]
 }
 
-func NewShop(r repository, s pb.UserClient, prod productpb.ProductClient, p paymentpb.PaymentClient) *Shop {
+func NewShop(r repository, s pb.UserClient, prod productpb.ProductClient, p paymentpb.PaymentClient, log *zap.Logger) *Shop {
 	return &Shop{
-		repo:    r,
-		service: s,
-		product: prod,
-		payment: p,
+		repo:       r,
+		service:    s,
+		product:    prod,
+		payment:    p,
+		log:        log,
+		products:   newCache[int, *entity.Product](productCacheTTL),
+		principals: newCache[string, *pb.GetUserResponse](principalCacheTTL),
 	}
 }
 
 func (s *Shop) CreateRequest(ctx context.Context, request *entity.Create) (string, error) {
-	log := zap.NewNop()
+	log := logger.For(ctx, s.log)
+
+	for i, item := range request.Items {
+		prod, err := s.getProduct(ctx, item.ProductID)
+		if unavailable(err) {
+			// The Request is still created, and served with the live
+			// product until the product service is back.
+			log.Error(
+				"product service unavailable, creating request without snapshot",
+				zap.Int("product_id", item.ProductID),
+				zap.Error(err),
+			)
+			metrics.SnapshotSkipped()
+			continue
+		}
+		if err != nil {
+			log.Error(
+				"error to get product",
+				zap.Error(err),
+			)
+			return "", err
+		}
+		request.Items[i].Snapshot = newSnapshot(prod)
+	}
 
 	items := []*paymentpb.Item{}
 	for _, item := range request.Items {
@@ -78,16 +122,20 @@ func (s *Shop) CreateRequest(ctx context.Context, request *entity.Create) (strin
 		}
 	}
 
+	amount := 0.0
+	for _, item := range request.Items {
+		amount += item.Price + item.Tax
+	}
+	metrics.OrderCreated(len(request.Items), amount)
+
 	return payment.Id, nil
 }
 
 func (s *Shop) UpdateRequest(ctx context.Context, id string, request *entity.Request) error {
-	log := zap.NewNop()
+	log := logger.For(ctx, s.log)
 
 	admin := ctx.Value(config.EmailHeader)
-	result, err := s.service.GetUser(ctx, &pb.GetUserRequest{
-		Email: admin.(string),
-	})
+	result, err := s.getUser(ctx, admin.(string))
 	if err != nil {
 		log.Error(
 			"error getting admin",
@@ -99,7 +147,7 @@ func (s *Shop) UpdateRequest(ctx context.Context, id string, request *entity.Req
 		log.Error(
 			"unauthorized action",
 		)
-		return errors.New("unauthorized action")
+		return entity.ErrUnauthorized
 	}
 
 	requestID, err := strconv.Atoi(id)
@@ -111,6 +159,29 @@ func (s *Shop) UpdateRequest(ctx context.Context, id string, request *entity.Req
 		return err
 	}
 
+	current, err := s.repo.GetRequest(ctx, requestID)
+	if err != nil {
+		log.Error(
+			"error to get request",
+			zap.Error(err),
+		)
+		return err
+	}
+
+	// The status is checked at the current version, so a concurrent change
+	// fails the update instead of skipping the check.
+	err = checkStatusUpdate(*current, request.Version, request.Status)
+	if err != nil {
+		log.Error(
+			"error validating status",
+			zap.Error(err),
+		)
+		return err
+	}
+	if request.Version == 0 {
+		request.Version = current.Version
+	}
+
 	err = s.repo.UpdateRequest(ctx, requestID, request)
 	if err != nil {
 		log.Error(
@@ -124,7 +195,7 @@ func (s *Shop) UpdateRequest(ctx context.Context, id string, request *entity.Req
 }
 
 func (s *Shop) ConfirmRequest(ctx context.Context, id string) error {
-	log := zap.NewNop()
+	log := logger.For(ctx, s.log)
 
 	err := s.repo.ConfirmRequests(ctx, id)
 	if err != nil {
@@ -157,28 +228,113 @@ func (s *Shop) ConfirmRequest(ctx context.Context, id string) error {
 		}
 	}
 
+	metrics.OrderConfirmed()
+
 	return nil
 }
 
-func (s *Shop) SearchRequest(ctx context.Context, storeID, status, initialDate, endDate string) ([]entity.Request, error) {
-	log := zap.NewNop()
+// GetRequest gets a Request, for an admin or the user who made it.
+func (s *Shop) GetRequest(ctx context.Context, id string) (*entity.Request, error) {
+	log := logger.For(ctx, s.log)
+
+	email := ctx.Value(config.EmailHeader)
+	user, err := s.getUser(ctx, email.(string))
+	if err != nil {
+		log.Error(
+			"error getting user",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	requestID, err := strconv.Atoi(id)
+	if err != nil {
+		log.Error(
+			"error validating id",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	result, err := s.repo.GetRequest(ctx, requestID)
+	if err != nil {
+		log.Error(
+			"error to get request",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	if !user.IsAdmin && strconv.Itoa(result.UserID) != user.Id {
+		log.Error(
+			"unauthorized action",
+		)
+		return nil, entity.ErrUnauthorized
+	}
+
+	requests := []entity.Request{*result}
+	s.enrichRequests(ctx, requests, false)
+
+	return &requests[0], nil
+}
+
+// GetPaymentRequests gets the Requests of a payment, for an admin or the
+// user who made it.
+func (s *Shop) GetPaymentRequests(ctx context.Context, paymentID string) ([]entity.Request, error) {
+	log := logger.For(ctx, s.log)
+
+	email := ctx.Value(config.EmailHeader)
+	user, err := s.getUser(ctx, email.(string))
+	if err != nil {
+		log.Error(
+			"error getting user",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	result, err := s.repo.GetRequestByPayment(ctx, paymentID)
+	if err != nil {
+		log.Error(
+			"error to get requests",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	if !user.IsAdmin {
+		for _, req := range result {
+			if strconv.Itoa(req.UserID) != user.Id {
+				log.Error(
+					"unauthorized action",
+				)
+				return nil, entity.ErrUnauthorized
+			}
+		}
+	}
+
+	s.enrichRequests(ctx, result, false)
+
+	return result, nil
+}
+
+func (s *Shop) SearchRequest(ctx context.Context, storeID string, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error) {
+	log := logger.For(ctx, s.log)
 
 	admin := ctx.Value(config.EmailHeader)
-	user, err := s.service.GetUser(ctx, &pb.GetUserRequest{
-		Email: admin.(string),
-	})
+	user, err := s.getUser(ctx, admin.(string))
 	if err != nil {
 		log.Error(
 			"error getting admin",
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, entity.PageInfo{}, err
 	}
 	if !user.IsAdmin {
 		log.Error(
 			"unauthorized action",
 		)
-		return nil, errors.New("unauthorized action")
+		return nil, entity.PageInfo{}, entity.ErrUnauthorized
 	}
 
 	id, err := strconv.Atoi(storeID)
@@ -187,79 +343,44 @@ func (s *Shop) SearchRequest(ctx context.Context, storeID, status, initialDate,
 			"error validating id",
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, entity.PageInfo{}, err
 	}
+	filter.StoreID = id
 
-	var init time.Time
-	if initialDate != "" {
-		init, err = time.Parse(time.RFC3339, initialDate)
-		if err != nil {
-			log.Error(
-				"error validating initial date",
-				zap.Error(err),
-			)
-			return nil, err
-		}
+	err = validateRequestFilter(filter)
+	if err != nil {
+		log.Error(
+			"error validating filter",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
 	}
 
-	var end time.Time
-	if endDate != "" {
-		end, err = time.Parse(time.RFC3339, endDate)
-		if err != nil {
-			log.Error(
-				"error validating end date",
-				zap.Error(err),
-			)
-			return nil, err
-		}
+	err = validatePage(page)
+	if err != nil {
+		log.Error(
+			"error validating page",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
 	}
 
-	result, err := s.repo.SearchRequest(ctx, id, status, init, end)
+	result, info, err := s.repo.SearchRequest(ctx, filter, page)
 	if err != nil {
 		log.Error(
 			"error to search requests",
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, entity.PageInfo{}, err
 	}
 
-	for i, res := range result {
-		prod, err := s.product.GetProduct(ctx, &productpb.GetProductRequest{Id: strconv.Itoa(res.ProductID)})
-		if err != nil {
-			log.Error(
-				"error to get product",
-				zap.Error(err),
-			)
-			return nil, err
-		}
+	s.enrichRequests(ctx, result, filter.Refresh)
 
-		imgs := []entity.Image{}
-		for _, img := range prod.Images {
-			imgs = append(imgs, entity.Image{
-				ID:        int(img.Id),
-				ImagePath: img.ImagePath,
-				ProductID: int(img.ProductId),
-			})
-		}
-		result[i].Product = &entity.Product{
-			ID:          int(prod.Id),
-			Name:        prod.Name,
-			Description: prod.Description,
-			Categories:  prod.Categories,
-			Size:        prod.Size,
-			Price:       float64(prod.Price),
-			Tax:         float64(prod.Tax),
-			Available:   prod.Available,
-			StoreID:     int(prod.StoreId),
-			Images:      imgs,
-		}
-	}
-
-	return result, nil
+	return result, info, nil
 }
 
-func (s *Shop) SearchProfileRequest(ctx context.Context, profileID, status, initialDate, endDate string) ([]entity.Request, error) {
-	log := zap.NewNop()
+func (s *Shop) SearchProfileRequest(ctx context.Context, profileID string, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error) {
+	log := logger.For(ctx, s.log)
 
 	id, err := strconv.Atoi(profileID)
 	if err != nil {
@@ -267,84 +388,102 @@ func (s *Shop) SearchProfileRequest(ctx context.Context, profileID, status, init
 			"error validating id",
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, entity.PageInfo{}, err
 	}
+	filter.UserID = id
 
-	var init time.Time
-	if initialDate != "" {
-		init, err = time.Parse(time.RFC3339, initialDate)
-		if err != nil {
-			log.Error(
-				"error validating initial date",
-				zap.Error(err),
-			)
-			return nil, err
-		}
+	err = validateRequestFilter(filter)
+	if err != nil {
+		log.Error(
+			"error validating filter",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
 	}
 
-	var end time.Time
-	if endDate != "" {
-		end, err = time.Parse(time.RFC3339, endDate)
-		if err != nil {
-			log.Error(
-				"error validating end date",
-				zap.Error(err),
-			)
-			return nil, err
-		}
+	err = validatePage(page)
+	if err != nil {
+		log.Error(
+			"error validating page",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
 	}
 
-	result, err := s.repo.SearchProfileRequest(ctx, id, status, init, end)
+	result, info, err := s.repo.SearchRequest(ctx, filter, page)
 	if err != nil {
 		log.Error(
 			"error to search requests",
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, entity.PageInfo{}, err
 	}
 
-	for i, res := range result {
-		prod, err := s.product.GetProduct(ctx, &productpb.GetProductRequest{Id: strconv.Itoa(res.ProductID)})
-		if err != nil {
-			log.Error(
-				"error to get product",
-				zap.Error(err),
-			)
-			return nil, err
-		}
+	s.enrichRequests(ctx, result, filter.Refresh)
 
-		imgs := []entity.Image{}
-		for _, img := range prod.Images {
-			imgs = append(imgs, entity.Image{
-				ID:        int(img.Id),
-				ImagePath: img.ImagePath,
-				ProductID: int(img.ProductId),
-			})
-		}
-		result[i].Product = &entity.Product{
-			ID:          int(prod.Id),
-			Name:        prod.Name,
-			Description: prod.Description,
-			Categories:  prod.Categories,
-			Size:        prod.Size,
-			Price:       float64(prod.Price),
-			Tax:         float64(prod.Tax),
-			Available:   prod.Available,
-			StoreID:     int(prod.StoreId),
-			Images:      imgs,
-		}
+	return result, info, nil
+}
+
+func (s *Shop) SearchAllRequest(ctx context.Context, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error) {
+	log := logger.For(ctx, s.log)
+
+	admin := ctx.Value(config.EmailHeader)
+	user, err := s.getUser(ctx, admin.(string))
+	if err != nil {
+		log.Error(
+			"error getting admin",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
+	}
+	if !user.IsAdmin {
+		log.Error(
+			"unauthorized action",
+		)
+		return nil, entity.PageInfo{}, entity.ErrUnauthorized
 	}
 
-	return result, nil
+	// Admins may list unpaid checkouts; store and profile searches never
+	// return them.
+	filter.Unpaid = slices.Contains(filter.Status, entity.StatusCreated)
+
+	err = validateRequestFilter(filter)
+	if err != nil {
+		log.Error(
+			"error validating filter",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
+	}
+
+	err = validatePage(page)
+	if err != nil {
+		log.Error(
+			"error validating page",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
+	}
+
+	result, info, err := s.repo.SearchRequest(ctx, filter, page)
+	if err != nil {
+		log.Error(
+			"error to search requests",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
+	}
+
+	s.enrichRequests(ctx, result, filter.Refresh)
+
+	return result, info, nil
 }
 
 func (s *Shop) CreatePayment(ctx context.Context, payment *entity.Payment) (int, error) {
-	log := zap.NewNop()
+	log := logger.For(ctx, s.log)
 
 	admin := ctx.Value(config.EmailHeader)
-	user, err := s.service.GetUser(ctx, &pb.GetUserRequest{
-		Email: admin.(string),
-	})
+	user, err := s.getUser(ctx, admin.(string))
 	if err != nil {
 		log.Error(
 			"error getting admin",
@@ -356,7 +495,7 @@ func (s *Shop) CreatePayment(ctx context.Context, payment *entity.Payment) (int,
 		log.Error(
 			"unauthorized action",
 		)
-		return 0, errors.New("unauthorized action")
+		return 0, entity.ErrUnauthorized
 	}
 
 	id, err := s.repo.CreatePayment(ctx, payment)
@@ -372,12 +511,10 @@ func (s *Shop) CreatePayment(ctx context.Context, payment *entity.Payment) (int,
 }
 
 func (s *Shop) UpdatePayment(ctx context.Context, id string, payment *entity.Payment) error {
-	log := zap.NewNop()
+	log := logger.For(ctx, s.log)
 
 	admin := ctx.Value(config.EmailHeader)
-	user, err := s.service.GetUser(ctx, &pb.GetUserRequest{
-		Email: admin.(string),
-	})
+	user, err := s.getUser(ctx, admin.(string))
 	if err != nil {
 		log.Error(
 			"error getting admin",
@@ -389,7 +526,7 @@ func (s *Shop) UpdatePayment(ctx context.Context, id string, payment *entity.Pay
 		log.Error(
 			"unauthorized action",
 		)
-		return errors.New("unauthorized action")
+		return entity.ErrUnauthorized
 	}
 
 	paymentID, err := strconv.Atoi(id)
@@ -413,13 +550,12 @@ func (s *Shop) UpdatePayment(ctx context.Context, id string, payment *entity.Pay
 	return nil
 }
 
-func (s *Shop) GetPayments(ctx context.Context, storeID string) ([]entity.Payment, error) {
-	log := zap.NewNop()
+// GetPayment gets a Payment, for an admin.
+func (s *Shop) GetPayment(ctx context.Context, id string) (*entity.Payment, error) {
+	log := logger.For(ctx, s.log)
 
 	admin := ctx.Value(config.EmailHeader)
-	user, err := s.service.GetUser(ctx, &pb.GetUserRequest{
-		Email: admin.(string),
-	})
+	user, err := s.getUser(ctx, admin.(string))
 	if err != nil {
 		log.Error(
 			"error getting admin",
@@ -431,10 +567,10 @@ func (s *Shop) GetPayments(ctx context.Context, storeID string) ([]entity.Paymen
 		log.Error(
 			"unauthorized action",
 		)
-		return nil, errors.New("unauthorized action")
+		return nil, entity.ErrUnauthorized
 	}
 
-	id, err := strconv.Atoi(storeID)
+	paymentID, err := strconv.Atoi(id)
 	if err != nil {
 		log.Error(
 			"error validating id",
@@ -443,10 +579,10 @@ func (s *Shop) GetPayments(ctx context.Context, storeID string) ([]entity.Paymen
 		return nil, err
 	}
 
-	result, err := s.repo.GetPayments(ctx, id)
+	result, err := s.repo.GetPayment(ctx, paymentID)
 	if err != nil {
 		log.Error(
-			"error to get payments",
+			"error to get payment",
 			zap.Error(err),
 		)
 		return nil, err
@@ -455,59 +591,130 @@ func (s *Shop) GetPayments(ctx context.Context, storeID string) ([]entity.Paymen
 	return result, nil
 }
 
-func (s *Shop) SearchPayment(ctx context.Context, status, initialDate, endDate string) ([]entity.Payment, error) {
-	log := zap.NewNop()
+func (s *Shop) GetPayments(ctx context.Context, storeID string, page entity.Page) ([]entity.Payment, entity.PageInfo, error) {
+	log := logger.For(ctx, s.log)
 
 	admin := ctx.Value(config.EmailHeader)
-	user, err := s.service.GetUser(ctx, &pb.GetUserRequest{
-		Email: admin.(string),
-	})
+	user, err := s.getUser(ctx, admin.(string))
 	if err != nil {
 		log.Error(
 			"error getting admin",
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, entity.PageInfo{}, err
 	}
 	if !user.IsAdmin {
 		log.Error(
 			"unauthorized action",
 		)
-		return nil, errors.New("unauthorized action")
+		return nil, entity.PageInfo{}, entity.ErrUnauthorized
 	}
 
-	var init time.Time
-	if initialDate != "" {
-		init, err = time.Parse(time.RFC3339, initialDate)
-		if err != nil {
-			log.Error(
-				"error validating initial date",
-				zap.Error(err),
-			)
-			return nil, err
-		}
+	id, err := strconv.Atoi(storeID)
+	if err != nil {
+		log.Error(
+			"error validating id",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
 	}
 
-	var end time.Time
-	if endDate != "" {
-		end, err = time.Parse(time.RFC3339, endDate)
-		if err != nil {
-			log.Error(
-				"error validating end date",
-				zap.Error(err),
-			)
-			return nil, err
-		}
+	err = validatePage(page)
+	if err != nil {
+		log.Error(
+			"error validating page",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
+	}
+
+	result, info, err := s.repo.GetPayments(ctx, id, page)
+	if err != nil {
+		log.Error(
+			"error to get payments",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
+	}
+
+	return result, info, nil
+}
+
+func (s *Shop) SearchPayment(ctx context.Context, filter entity.PaymentFilter, page entity.Page) ([]entity.Payment, entity.PageInfo, error) {
+	log := logger.For(ctx, s.log)
+
+	admin := ctx.Value(config.EmailHeader)
+	user, err := s.getUser(ctx, admin.(string))
+	if err != nil {
+		log.Error(
+			"error getting admin",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
+	}
+	if !user.IsAdmin {
+		log.Error(
+			"unauthorized action",
+		)
+		return nil, entity.PageInfo{}, entity.ErrUnauthorized
 	}
 
-	result, err := s.repo.SearchPayment(ctx, status, init, end)
+	err = validatePaymentFilter(filter)
+	if err != nil {
+		log.Error(
+			"error validating filter",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
+	}
+
+	err = validatePage(page)
+	if err != nil {
+		log.Error(
+			"error validating page",
+			zap.Error(err),
+		)
+		return nil, entity.PageInfo{}, err
+	}
+
+	result, info, err := s.repo.SearchPayment(ctx, filter, page)
 	if err != nil {
 		log.Error(
 			"error to search payments",
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, entity.PageInfo{}, err
 	}
 
-	return result, nil
+	return result, info, nil
+}
+
+func validatePage(page entity.Page) error {
+	if page.Limit < 0 || page.Limit > entity.MaxLimit {
+		return fmt.Errorf("limit must be between 0 and %d", entity.MaxLimit)
+	}
+	if page.Sort != "" && page.Sort != entity.SortAsc && page.Sort != entity.SortDesc {
+		return errors.New("invalid sort order")
+	}
+	return nil
+}
+
+func validatePaymentFilter(filter entity.PaymentFilter) error {
+	if filter.MinTotal != nil && filter.MaxTotal != nil && *filter.MinTotal > *filter.MaxTotal {
+		return errors.New("minimum total greater than maximum total")
+	}
+	if !filter.InitialDate.IsZero() && !filter.EndDate.IsZero() && filter.InitialDate.After(filter.EndDate) {
+		return errors.New("initial date after end date")
+	}
+	return nil
+}
+
+func validateRequestFilter(filter entity.RequestFilter) error {
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return errors.New("minimum price greater than maximum price")
+	}
+	if !filter.InitialDate.IsZero() && !filter.EndDate.IsZero() && filter.InitialDate.After(filter.EndDate) {
+		return errors.New("initial date after end date")
+	}
+	return nil
 }
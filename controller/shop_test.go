@@ -0,0 +1,317 @@
+package controller_test
+
+import (
+	"context"
+	"errors"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/fake"
+	"github.com/restore/shop/fixture"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+	"testing"
+)
+
+func TestCreateRequest(t *testing.T) {
+	shop := fixture.NewShop()
+	shop.Products.Products[10] = fixture.Product(10, 1)
+	shop.Products.Products[11] = fixture.Product(11, 1)
+
+	paymentID, err := shop.CreateRequest(fixture.Context(fixture.UserEmail), &entity.Create{
+		Items: []entity.Request{
+			{Price: 100, Tax: 10, StoreID: 1, ProductID: 10, UserID: 2},
+			{Price: 50, Tax: 5, StoreID: 1, ProductID: 11, UserID: 2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	if paymentID != "1" {
+		t.Errorf("payment = %q, want 1", paymentID)
+	}
+
+	payments := shop.Payments.Payments()
+	if len(payments) != 1 || len(payments[0]) != 2 || payments[0][0].UnitPrice != 110 || payments[0][1].UnitPrice != 55 {
+		t.Errorf("payments = %v, want one payment of 110 and 55", payments)
+	}
+
+	requests := shop.Repository.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(requests))
+	}
+	for _, req := range requests {
+		if req.PaymentID != paymentID || req.Status != "created" {
+			t.Errorf("request = %+v, want created on payment %s", req, paymentID)
+		}
+		if req.Snapshot.Name != "Product "+strconv.Itoa(req.ProductID) || !req.Snapshot.Available || len(req.Snapshot.Images) != 1 {
+			t.Errorf("snapshot = %+v, want the product at checkout", req.Snapshot)
+		}
+	}
+}
+
+func TestCreateRequestProductUnavailable(t *testing.T) {
+	shop := fixture.NewShop()
+	shop.Products.Err = status.Error(codes.Unavailable, "connection refused")
+
+	_, err := shop.CreateRequest(fixture.Context(fixture.UserEmail), &entity.Create{
+		Items: []entity.Request{{Price: 100, Tax: 10, StoreID: 1, ProductID: 10, UserID: 2}},
+	})
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	requests := shop.Repository.Requests()
+	if len(requests) != 1 || requests[0].Snapshot.Name != "" {
+		t.Errorf("requests = %+v, want one request without snapshot", requests)
+	}
+}
+
+func TestCreateRequestProductNotFound(t *testing.T) {
+	shop := fixture.NewShop()
+
+	_, err := shop.CreateRequest(fixture.Context(fixture.UserEmail), &entity.Create{
+		Items: []entity.Request{{Price: 100, Tax: 10, StoreID: 1, ProductID: 10, UserID: 2}},
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("error = %v, want NotFound", err)
+	}
+	if len(shop.Payments.Payments()) != 0 || len(shop.Repository.Requests()) != 0 {
+		t.Error("payment or request created for a missing product")
+	}
+}
+
+func TestConfirmRequest(t *testing.T) {
+	ctx := context.Background()
+	shop := fixture.NewShop()
+	shop.Products.Products[10] = fixture.Product(10, 1)
+	for _, productID := range []int{10, 11} {
+		req := fixture.Request(1, productID, 2, "created")
+		err := shop.Repository.CreateRequest(ctx, &req)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+	}
+
+	err := shop.ConfirmRequest(ctx, "1")
+	if err != nil {
+		t.Fatalf("error confirming request: %v", err)
+	}
+
+	for _, req := range shop.Repository.Requests() {
+		if req.Status != "preparing" {
+			t.Errorf("status = %q, want preparing", req.Status)
+		}
+	}
+	unavailable := shop.Products.Unavailable()
+	if len(unavailable) != 2 || unavailable[0] != 10 || unavailable[1] != 11 {
+		t.Errorf("unavailable = %v, want [10 11]", unavailable)
+	}
+	if shop.Products.Products[10].Available {
+		t.Error("product 10 still available")
+	}
+}
+
+func TestUpdateRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		email   string
+		id      string
+		status  string
+		version int
+		want    error
+	}{
+		{"admin", fixture.AdminEmail, "1", "preparing", 0, nil},
+		{"admin with version", fixture.AdminEmail, "1", "preparing", 1, nil},
+		{"unpaid request shipped", fixture.AdminEmail, "1", "shipped", 0, entity.ErrInvalidStatus},
+		{"stale version", fixture.AdminEmail, "1", "preparing", 2, entity.ErrVersionConflict},
+		{"missing request", fixture.AdminEmail, "2", "preparing", 0, fake.ErrNotFound},
+		{"not admin", fixture.UserEmail, "1", "preparing", 0, entity.ErrUnauthorized},
+		{"unknown caller", "nobody@restore.test", "1", "preparing", 0, entity.ErrUnauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shop := fixture.NewShop()
+			req := fixture.Request(1, 10, 2, "created")
+			err := shop.Repository.CreateRequest(context.Background(), &req)
+			if err != nil {
+				t.Fatalf("error creating request: %v", err)
+			}
+
+			update := &entity.Request{Status: tt.status, Version: tt.version}
+			err = shop.UpdateRequest(fixture.Context(tt.email), tt.id, update)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				return
+			}
+
+			stored := shop.Repository.Requests()[0]
+			if stored.Status != tt.status || stored.Version != 2 {
+				t.Errorf("request = %+v, want %s at version 2", stored, tt.status)
+			}
+		})
+	}
+}
+
+func TestGetRequest(t *testing.T) {
+	shop := fixture.NewShop()
+	buyer, _ := strconv.Atoi(fixture.UserID)
+	req := fixture.Request(1, 10, buyer, "preparing")
+	err := shop.Repository.CreateRequest(context.Background(), &req)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	shop.Users.Users["other@restore.test"] = fixture.Users()[fixture.UserEmail]
+	shop.Users.Users["other@restore.test"].Id = "3"
+
+	tests := []struct {
+		name  string
+		email string
+		id    string
+		want  error
+	}{
+		{"admin", fixture.AdminEmail, "1", nil},
+		{"buyer", fixture.UserEmail, "1", nil},
+		{"other buyer", "other@restore.test", "1", entity.ErrUnauthorized},
+		{"missing request", fixture.AdminEmail, "2", fake.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := shop.GetRequest(fixture.Context(tt.email), tt.id)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (result.ID != req.ID || result.Version != 1) {
+				t.Errorf("request = %+v, want request %d at version 1", result, req.ID)
+			}
+		})
+	}
+}
+
+func TestGetPaymentRequests(t *testing.T) {
+	shop := fixture.NewShop()
+	buyer, _ := strconv.Atoi(fixture.UserID)
+	req := fixture.Request(1, 10, buyer, "preparing")
+	err := shop.Repository.CreateRequest(context.Background(), &req)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	shop.Users.Users["other@restore.test"] = fixture.Users()[fixture.UserEmail]
+	shop.Users.Users["other@restore.test"].Id = "3"
+
+	tests := []struct {
+		name  string
+		email string
+		want  error
+	}{
+		{"admin", fixture.AdminEmail, nil},
+		{"buyer", fixture.UserEmail, nil},
+		{"other buyer", "other@restore.test", entity.ErrUnauthorized},
+		{"unknown caller", "nobody@restore.test", entity.ErrUnauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := shop.GetPaymentRequests(fixture.Context(tt.email), req.PaymentID)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && len(result) != 1 {
+				t.Errorf("requests = %d, want 1", len(result))
+			}
+		})
+	}
+}
+
+func TestSearchRequest(t *testing.T) {
+	ctx := context.Background()
+	shop := fixture.NewShop()
+	for _, storeID := range []int{1, 1, 2} {
+		req := fixture.Request(storeID, 10, 2, "preparing")
+		err := shop.Repository.CreateRequest(ctx, &req)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+	}
+
+	result, info, err := shop.SearchRequest(fixture.Context(fixture.AdminEmail), "1", entity.RequestFilter{}, entity.Page{})
+	if err != nil {
+		t.Fatalf("error searching requests: %v", err)
+	}
+	if len(result) != 2 || info.Total != 2 {
+		t.Errorf("requests = %d of %d, want 2 of 2", len(result), info.Total)
+	}
+
+	_, _, err = shop.SearchRequest(fixture.Context(fixture.UserEmail), "1", entity.RequestFilter{}, entity.Page{})
+	if !errors.Is(err, entity.ErrUnauthorized) {
+		t.Errorf("error = %v, want %v", err, entity.ErrUnauthorized)
+	}
+
+	_, _, err = shop.SearchRequest(fixture.Context(fixture.AdminEmail), "1", entity.RequestFilter{}, entity.Page{Limit: entity.MaxLimit + 1})
+	if err == nil {
+		t.Error("expected an error with a limit over the maximum")
+	}
+}
+
+func TestUpdatePayment(t *testing.T) {
+	ctx := context.Background()
+	shop := fixture.NewShop()
+	payment := fixture.Payment(1, 10, 100, "pending")
+	_, err := shop.Repository.CreatePayment(ctx, &payment)
+	if err != nil {
+		t.Fatalf("error creating payment: %v", err)
+	}
+
+	err = shop.UpdatePayment(fixture.Context(fixture.UserEmail), "1", &entity.Payment{Status: "paid"})
+	if !errors.Is(err, entity.ErrUnauthorized) {
+		t.Fatalf("error = %v, want %v", err, entity.ErrUnauthorized)
+	}
+
+	err = shop.UpdatePayment(fixture.Context(fixture.AdminEmail), "1", &entity.Payment{Status: "paid"})
+	if err != nil {
+		t.Fatalf("error updating payment: %v", err)
+	}
+	if stored := shop.Repository.Payments()[0]; stored.Status != "paid" {
+		t.Errorf("status = %q, want paid", stored.Status)
+	}
+}
+
+func TestSearchUnpaidRequests(t *testing.T) {
+	ctx := context.Background()
+	shop := fixture.NewShop()
+	for _, status := range []string{"created", "preparing"} {
+		req := fixture.Request(1, 10, 2, status)
+		err := shop.Repository.CreateRequest(ctx, &req)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+	}
+	filter := entity.RequestFilter{Status: []string{"created", "preparing"}}
+
+	tests := []struct {
+		name   string
+		search func() ([]entity.Request, entity.PageInfo, error)
+		want   int64
+	}{
+		{"store", func() ([]entity.Request, entity.PageInfo, error) {
+			return shop.SearchRequest(fixture.Context(fixture.AdminEmail), "1", filter, entity.Page{})
+		}, 1},
+		{"profile", func() ([]entity.Request, entity.PageInfo, error) {
+			return shop.SearchProfileRequest(fixture.Context(fixture.UserEmail), "2", filter, entity.Page{})
+		}, 1},
+		{"admin-wide", func() ([]entity.Request, entity.PageInfo, error) {
+			return shop.SearchAllRequest(fixture.Context(fixture.AdminEmail), filter, entity.Page{})
+		}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, info, err := tt.search()
+			if err != nil {
+				t.Fatalf("error searching requests: %v", err)
+			}
+			if info.Total != tt.want {
+				t.Errorf("total = %d, want %d", info.Total, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"context"
+	pb "github.com/ReStorePUC/protobucket/user"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+const principalCacheTTL = 5 * time.Minute
+
+// getUser gets the user of email from the user service. When the service is
+// unavailable it falls back to the last principal seen for email. A missing
+// or unknown email is an entity.ErrUnauthenticated.
+func (s *Shop) getUser(ctx context.Context, email string) (*pb.GetUserResponse, error) {
+	log := logger.For(ctx, s.log)
+
+	if email == "" {
+		return nil, entity.ErrUnauthenticated
+	}
+
+	user, err := s.service.GetUser(ctx, &pb.GetUserRequest{
+		Email: email,
+	})
+	if err == nil {
+		s.principals.set(email, user)
+		return user, nil
+	}
+
+	if unavailable(err) {
+		if cached, ok := s.principals.get(email); ok {
+			log.Warn(
+				"user service unavailable, using cached principal",
+				zap.Error(err),
+			)
+			return cached, nil
+		}
+	}
+	if status.Code(err) == codes.NotFound {
+		return nil, entity.ErrUnauthenticated
+	}
+	return nil, err
+}
+
+// unavailable reports whether err means an upstream can't be reached.
+func unavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
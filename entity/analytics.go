@@ -0,0 +1,75 @@
+package entity
+
+import "time"
+
+// Defaults of the platform analytics.
+const (
+	DefaultTopLimit       = 10
+	DefaultAbandonedAfter = 24 * time.Hour
+)
+
+// AnalyticsFilter represents the criteria of the platform analytics. Zero
+// dates are ignored.
+type AnalyticsFilter struct {
+	Period      string
+	InitialDate time.Time
+	EndDate     time.Time
+	// Limit is the number of stores or categories of a ranking.
+	Limit int
+	// AbandonedAfter is how long a checkout stays unpaid before it counts as
+	// abandoned.
+	AbandonedAfter time.Duration
+}
+
+// GMVPeriod represents the gross merchandise value sold on the platform in a
+// period, starting on the date Period.
+type GMVPeriod struct {
+	Period string  `json:"period"`
+	GMV    float64 `json:"gmv"`
+	Fees   float64 `json:"fees"`
+	Orders int64   `json:"orders"`
+	Stores int64   `json:"stores"`
+}
+
+// StoreSales represents the sales of a store, in a ranking of stores.
+type StoreSales struct {
+	StoreID    int     `json:"store_id"`
+	GrossSales float64 `json:"gross_sales"`
+	Orders     int64   `json:"orders"`
+	Requests   int64   `json:"requests"`
+}
+
+// CategorySales represents the sales of the products of a category, as in
+// their snapshot, in a ranking of categories.
+type CategorySales struct {
+	Category   string  `json:"category"`
+	GrossSales float64 `json:"gross_sales"`
+	Requests   int64   `json:"requests"`
+}
+
+// CheckoutStats represents how many checkouts were paid, moving their
+// Requests from created to preparing, and how many were abandoned.
+type CheckoutStats struct {
+	Checkouts      int64   `json:"checkouts"`
+	Converted      int64   `json:"converted"`
+	ConversionRate float64 `json:"conversion_rate"`
+	Abandoned      int64   `json:"abandoned"`
+	AbandonedValue float64 `json:"abandoned_value"`
+	AbandonedRate  float64 `json:"abandoned_rate"`
+}
+
+// RefundStats represents how many paid Requests were refunded.
+type RefundStats struct {
+	Paid          int64   `json:"paid"`
+	Refunded      int64   `json:"refunded"`
+	RefundedValue float64 `json:"refunded_value"`
+	RefundRate    float64 `json:"refund_rate"`
+}
+
+// Rate returns part over total, or 0 without a total.
+func Rate(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
@@ -0,0 +1,26 @@
+package entity
+
+// MaxBulkUpdates is the maximum number of updates of a bulk request.
+const MaxBulkUpdates = 500
+
+// StatusUpdate represents an update of the status and track of a Request.
+// A zero Version updates any version.
+type StatusUpdate struct {
+	ID      int    `json:"id"`
+	Status  string `json:"status"`
+	Track   string `json:"track"`
+	Version int    `json:"version"`
+}
+
+type BulkUpdate struct {
+	Items []StatusUpdate `json:"items"`
+}
+
+// StatusUpdateResult represents the outcome of a StatusUpdate. Version is the
+// new version of an applied update; Error is set when it wasn't applied.
+type StatusUpdateResult struct {
+	ID      int    `json:"id"`
+	Applied bool   `json:"applied"`
+	Version int    `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
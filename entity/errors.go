@@ -0,0 +1,47 @@
+package entity
+
+import "errors"
+
+// ErrVersionConflict means an update expected a version other than the
+// current one, because the record was changed concurrently.
+var ErrVersionConflict = errors.New("version conflict: the record was changed by someone else")
+
+// ErrBulkRejected means an atomic bulk update had invalid items, so none was
+// applied.
+var ErrBulkRejected = errors.New("some updates are invalid, none was applied")
+
+// ErrInvalidStatus means a Request cannot move from its status to the
+// requested one.
+var ErrInvalidStatus = errors.New("invalid status change")
+
+// ErrUnauthenticated means the caller is missing or unknown.
+var ErrUnauthenticated = errors.New("unauthenticated caller")
+
+// ErrUnauthorized means the caller may not perform the action.
+var ErrUnauthorized = errors.New("unauthorized action")
+
+// ErrRequestNotFound means no Request has the given ID.
+var ErrRequestNotFound = errors.New("request not found")
+
+// ErrStorage marks errors of the database, as opposed to errors of a call.
+var ErrStorage = errors.New("storage error")
+
+type storageError struct {
+	err error
+}
+
+func (e storageError) Error() string {
+	return e.err.Error()
+}
+
+func (e storageError) Unwrap() []error {
+	return []error{e.err, ErrStorage}
+}
+
+// StorageError marks err as an ErrStorage, keeping its message.
+func StorageError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return storageError{err}
+}
@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// PaymentFilter represents the criteria of a payment search.
+// Zero values are ignored.
+type PaymentFilter struct {
+	StoreID     int
+	ProductID   int
+	Status      []string
+	MinTotal    *float64
+	MaxTotal    *float64
+	InitialDate time.Time
+	EndDate     time.Time
+}
+
+// RequestFilter represents the criteria of a request search.
+// Zero values are ignored. Requests still in the "created" status, unpaid
+// checkouts, are only returned with Unpaid.
+type RequestFilter struct {
+	StoreID     int
+	UserID      int
+	ProductID   int
+	PaymentID   string
+	Status      []string
+	MinPrice    *float64
+	MaxPrice    *float64
+	InitialDate time.Time
+	EndDate     time.Time
+
+	// Unpaid includes the Requests still in the "created" status. Only the
+	// admin-wide search sets it, when "created" is listed in Status.
+	Unpaid bool
+
+	// Refresh loads products from the product service instead of the
+	// snapshot taken at checkout.
+	Refresh bool
+}
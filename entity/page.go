@@ -0,0 +1,22 @@
+package entity
+
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// Page represents the pagination parameters of a search.
+type Page struct {
+	Limit  int
+	Cursor string
+	Sort   string
+}
+
+// PageInfo represents data about the result of a paginated search.
+type PageInfo struct {
+	Total      int64
+	NextCursor string
+}
@@ -11,4 +11,7 @@ type Payment struct {
 	CreatedAt time.Time `json:"created_at"`
 	StoreID   int       `json:"store_id"`
 	ProductID int       `json:"product_id"`
+	Product   *Product  `json:"product,omitempty" gorm:"-"`
+	// Version is incremented by every update, for optimistic locking.
+	Version int `json:"version"`
 }
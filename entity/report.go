@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// Periods of a report.
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// UnsoldStatuses are the statuses of Requests left out of the sales: unpaid
+// or refunded.
+var UnsoldStatuses = append([]string{StatusRefunded}, UnpaidStatuses...)
+
+// ReportFilter represents the criteria of a sales report. Zero dates are
+// ignored.
+type ReportFilter struct {
+	StoreID     int
+	Period      string
+	InitialDate time.Time
+	EndDate     time.Time
+}
+
+// SalesPeriod represents the sales of a store in a period, starting on the
+// date Period. Fees are the platform Tax of each Request; NetPayout is what
+// is owed to the store.
+type SalesPeriod struct {
+	Period        string           `json:"period,omitempty"`
+	GrossSales    float64          `json:"gross_sales"`
+	Fees          float64          `json:"fees"`
+	NetPayout     float64          `json:"net_payout"`
+	Orders        int64            `json:"orders"`
+	Requests      int64            `json:"requests"`
+	AverageTicket float64          `json:"average_ticket"`
+	Statuses      map[string]int64 `json:"statuses" gorm:"-"`
+}
+
+// SalesReport represents the sales of a store per period and in total.
+type SalesReport struct {
+	StoreID int           `json:"store_id"`
+	Period  string        `json:"period"`
+	Periods []SalesPeriod `json:"periods"`
+	Total   SalesPeriod   `json:"total"`
+}
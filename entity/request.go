@@ -15,6 +15,11 @@ type Request struct {
 	ProductID int       `json:"product_id"`
 	UserID    int       `json:"user_id"`
 	Product   *Product  `json:"product"`
+	Warning   string    `json:"warning,omitempty" gorm:"-"`
+	// Version is incremented by every update, for optimistic locking.
+	Version int `json:"version"`
+
+	Snapshot ProductSnapshot `json:"-" gorm:"embedded;embeddedPrefix:product_"`
 }
 
 type Create struct {
@@ -35,6 +40,19 @@ type Product struct {
 	Images      []Image `json:"images"`
 }
 
+// ProductSnapshot represents data about a product at the moment it was requested.
+type ProductSnapshot struct {
+	Name        string
+	Description string
+	Categories  string
+	Size        string
+	Price       float64
+	Tax         float64
+	// Available is whether the product was available at checkout.
+	Available bool
+	Images    []Image `gorm:"serializer:json"`
+}
+
 // Image represents data about an image.
 type Image struct {
 	ID        int    `json:"id" gorm:"primaryKey"`
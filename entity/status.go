@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Statuses of a Request set by the shop. Requests are created at checkout and
+// move to preparing when their payment is confirmed. From there, stores send
+// their own fulfilment statuses, such as "shipped", until the Request ends
+// canceled or refunded.
+const (
+	StatusCreated   = "created"
+	StatusPreparing = "preparing"
+	StatusCanceled  = "canceled"
+	StatusRefunded  = "refunded"
+)
+
+// UnpaidStatuses are the statuses of Requests without a paid order: created
+// ones still waiting for their payment and canceled ones. Every other status,
+// including the ones stores send such as "shipped", counts as paid.
+var UnpaidStatuses = []string{StatusCreated, StatusCanceled}
+
+// ValidateTransition checks that a Request in status from can be updated to
+// status to, in single and bulk updates alike. Keeping the status only
+// updates the track. Only checkout creates Requests, unpaid ones can only be
+// confirmed or canceled, and canceled and refunded ones are final; stores
+// move paid Requests between their own statuses freely.
+func ValidateTransition(from, to string) error {
+	if to == "" {
+		return errors.New("status is required")
+	}
+	if from == to {
+		return nil
+	}
+
+	unpaid := from == StatusCreated && to != StatusPreparing && to != StatusCanceled
+	final := from == StatusCanceled || from == StatusRefunded
+	if to == StatusCreated || unpaid || final {
+		return fmt.Errorf("%w from %q to %q", ErrInvalidStatus, from, to)
+	}
+	return nil
+}
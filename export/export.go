@@ -0,0 +1,92 @@
+// Package export writes tabular data as CSV or XLSX, one row at a time, so
+// exports of any size are streamed instead of held in memory.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Writer writes rows of cells. Cells are strings, ints, float64s or
+// time.Times; Close must be called after the last row.
+type Writer interface {
+	WriteRow(cells ...interface{}) error
+	Close() error
+}
+
+// New builds the Writer of format writing to w. Sheet names the XLSX sheet.
+func New(format string, w io.Writer, sheet string) (Writer, error) {
+	switch format {
+	case FormatCSV, "":
+		return NewCSV(w), nil
+	case FormatXLSX:
+		return NewXLSX(w, sheet)
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// ContentType returns the MIME type of format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func NewCSV(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteRow(cells ...interface{}) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = format(cell)
+		if _, ok := cell.(string); ok {
+			record[i] = escapeFormula(record[i])
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula prefixes text that spreadsheets would run as a formula with
+// a quote, so it is shown as typed.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func format(cell interface{}) string {
+	switch v := cell.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(cell)
+}
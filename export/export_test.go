@@ -0,0 +1,26 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSV(&buf)
+
+	err := w.WriteRow("=HYPERLINK(\"http://x\")", "+1", "-cmd", "@SUM(A1)", "plain", "", -5, -1.5, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("error writing row: %v", err)
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("error closing: %v", err)
+	}
+
+	want := "\"'=HYPERLINK(\"\"http://x\"\")\",'+1,'-cmd,'@SUM(A1),plain,,-5,-1.5,2023-01-01T00:00:00Z\n"
+	if buf.String() != want {
+		t.Errorf("csv = %q, want %q", buf.String(), want)
+	}
+}
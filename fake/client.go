@@ -0,0 +1,130 @@
+package fake
+
+import (
+	"context"
+	paymentpb "github.com/ReStorePUC/protobucket/payment"
+	productpb "github.com/ReStorePUC/protobucket/product"
+	pb "github.com/ReStorePUC/protobucket/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+	"sync"
+)
+
+// UserClient serves the Users set by email. Err, when set, is returned by
+// every call.
+type UserClient struct {
+	Users map[string]*pb.GetUserResponse
+	Err   error
+}
+
+func NewUserClient() *UserClient {
+	return &UserClient{
+		Users: map[string]*pb.GetUserResponse{},
+	}
+}
+
+func (c *UserClient) GetUser(ctx context.Context, in *pb.GetUserRequest, opts ...grpc.CallOption) (*pb.GetUserResponse, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+
+	user, ok := c.Users[in.Email]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "user %s not found", in.Email)
+	}
+	return user, nil
+}
+
+// ProductClient serves the Products set by ID and records the ones marked
+// as unavailable. Err, when set, is returned by every call.
+type ProductClient struct {
+	Products map[int]*productpb.GetProductResponse
+	Err      error
+
+	mu          sync.Mutex
+	unavailable []int
+}
+
+func NewProductClient() *ProductClient {
+	return &ProductClient{
+		Products: map[int]*productpb.GetProductResponse{},
+	}
+}
+
+func (c *ProductClient) GetProduct(ctx context.Context, in *productpb.GetProductRequest, opts ...grpc.CallOption) (*productpb.GetProductResponse, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+
+	id, _ := strconv.Atoi(in.Id)
+	product, ok := c.Products[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "product %s not found", in.Id)
+	}
+	return product, nil
+}
+
+func (c *ProductClient) UnavailableProduct(ctx context.Context, in *productpb.UnavailableProductRequest, opts ...grpc.CallOption) (*productpb.UnavailableProductResponse, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+
+	id, err := strconv.Atoi(in.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.unavailable = append(c.unavailable, id)
+	if product, ok := c.Products[id]; ok {
+		product.Available = false
+	}
+	return &productpb.UnavailableProductResponse{}, nil
+}
+
+// Unavailable returns the IDs of the Products marked as unavailable, in order.
+func (c *ProductClient) Unavailable() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]int{}, c.unavailable...)
+}
+
+// PaymentClient creates payments with sequential IDs and records the items
+// of each one. Err, when set, is returned by every call.
+type PaymentClient struct {
+	Err error
+
+	mu       sync.Mutex
+	payments [][]*paymentpb.Item
+}
+
+func NewPaymentClient() *PaymentClient {
+	return &PaymentClient{}
+}
+
+func (c *PaymentClient) CreatePayment(ctx context.Context, in *paymentpb.CreatePaymentRequest, opts ...grpc.CallOption) (*paymentpb.CreatePaymentResponse, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.payments = append(c.payments, in.Items)
+	return &paymentpb.CreatePaymentResponse{
+		Id: strconv.Itoa(len(c.payments)),
+	}, nil
+}
+
+// Payments returns the items of every created payment, in order.
+func (c *PaymentClient) Payments() [][]*paymentpb.Item {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([][]*paymentpb.Item{}, c.payments...)
+}
@@ -0,0 +1,189 @@
+package fake
+
+import (
+	"context"
+	"github.com/restore/shop/entity"
+)
+
+// Controller calls the function set for each method. Methods without a
+// function succeed with zero values.
+type Controller struct {
+	CreateRequestFunc        func(ctx context.Context, request *entity.Create) (string, error)
+	UpdateRequestFunc        func(ctx context.Context, id string, request *entity.Request) error
+	GetRequestFunc           func(ctx context.Context, id string) (*entity.Request, error)
+	BulkUpdateRequestsFunc   func(ctx context.Context, updates []entity.StatusUpdate, atomic bool) ([]entity.StatusUpdateResult, error)
+	ConfirmRequestFunc       func(ctx context.Context, paymentID string) error
+	GetPaymentRequestsFunc   func(ctx context.Context, paymentID string) ([]entity.Request, error)
+	SearchRequestFunc        func(ctx context.Context, storeID string, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error)
+	SearchProfileRequestFunc func(ctx context.Context, profileID string, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error)
+	SearchAllRequestFunc     func(ctx context.Context, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error)
+	ExportRequestsFunc       func(ctx context.Context, storeID string, filter entity.RequestFilter, write func([]entity.Request) error) error
+
+	CreatePaymentFunc  func(ctx context.Context, payment *entity.Payment) (int, error)
+	UpdatePaymentFunc  func(ctx context.Context, id string, payment *entity.Payment) error
+	GetPaymentFunc     func(ctx context.Context, id string) (*entity.Payment, error)
+	GetPaymentsFunc    func(ctx context.Context, storeID string, page entity.Page) ([]entity.Payment, entity.PageInfo, error)
+	SearchPaymentFunc  func(ctx context.Context, filter entity.PaymentFilter, page entity.Page) ([]entity.Payment, entity.PageInfo, error)
+	ExportPaymentsFunc func(ctx context.Context, storeID string, filter entity.PaymentFilter, write func([]entity.Payment) error) error
+
+	SalesReportFunc   func(ctx context.Context, storeID string, filter entity.ReportFilter) (*entity.SalesReport, error)
+	GMVFunc           func(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.GMVPeriod, error)
+	TopStoresFunc     func(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.StoreSales, error)
+	TopCategoriesFunc func(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.CategorySales, error)
+	CheckoutsFunc     func(ctx context.Context, filter entity.AnalyticsFilter) (*entity.CheckoutStats, error)
+	RefundsFunc       func(ctx context.Context, filter entity.AnalyticsFilter) (*entity.RefundStats, error)
+}
+
+func (c *Controller) CreateRequest(ctx context.Context, request *entity.Create) (string, error) {
+	if c.CreateRequestFunc == nil {
+		return "", nil
+	}
+	return c.CreateRequestFunc(ctx, request)
+}
+
+func (c *Controller) UpdateRequest(ctx context.Context, id string, request *entity.Request) error {
+	if c.UpdateRequestFunc == nil {
+		return nil
+	}
+	return c.UpdateRequestFunc(ctx, id, request)
+}
+
+func (c *Controller) GetRequest(ctx context.Context, id string) (*entity.Request, error) {
+	if c.GetRequestFunc == nil {
+		return &entity.Request{}, nil
+	}
+	return c.GetRequestFunc(ctx, id)
+}
+
+func (c *Controller) BulkUpdateRequests(ctx context.Context, updates []entity.StatusUpdate, atomic bool) ([]entity.StatusUpdateResult, error) {
+	if c.BulkUpdateRequestsFunc == nil {
+		return []entity.StatusUpdateResult{}, nil
+	}
+	return c.BulkUpdateRequestsFunc(ctx, updates, atomic)
+}
+
+func (c *Controller) ConfirmRequest(ctx context.Context, paymentID string) error {
+	if c.ConfirmRequestFunc == nil {
+		return nil
+	}
+	return c.ConfirmRequestFunc(ctx, paymentID)
+}
+
+func (c *Controller) GetPaymentRequests(ctx context.Context, paymentID string) ([]entity.Request, error) {
+	if c.GetPaymentRequestsFunc == nil {
+		return []entity.Request{}, nil
+	}
+	return c.GetPaymentRequestsFunc(ctx, paymentID)
+}
+
+func (c *Controller) SearchRequest(ctx context.Context, storeID string, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error) {
+	if c.SearchRequestFunc == nil {
+		return []entity.Request{}, entity.PageInfo{}, nil
+	}
+	return c.SearchRequestFunc(ctx, storeID, filter, page)
+}
+
+func (c *Controller) SearchProfileRequest(ctx context.Context, profileID string, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error) {
+	if c.SearchProfileRequestFunc == nil {
+		return []entity.Request{}, entity.PageInfo{}, nil
+	}
+	return c.SearchProfileRequestFunc(ctx, profileID, filter, page)
+}
+
+func (c *Controller) SearchAllRequest(ctx context.Context, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error) {
+	if c.SearchAllRequestFunc == nil {
+		return []entity.Request{}, entity.PageInfo{}, nil
+	}
+	return c.SearchAllRequestFunc(ctx, filter, page)
+}
+
+func (c *Controller) CreatePayment(ctx context.Context, payment *entity.Payment) (int, error) {
+	if c.CreatePaymentFunc == nil {
+		return 0, nil
+	}
+	return c.CreatePaymentFunc(ctx, payment)
+}
+
+func (c *Controller) UpdatePayment(ctx context.Context, id string, payment *entity.Payment) error {
+	if c.UpdatePaymentFunc == nil {
+		return nil
+	}
+	return c.UpdatePaymentFunc(ctx, id, payment)
+}
+
+func (c *Controller) GetPayment(ctx context.Context, id string) (*entity.Payment, error) {
+	if c.GetPaymentFunc == nil {
+		return &entity.Payment{}, nil
+	}
+	return c.GetPaymentFunc(ctx, id)
+}
+
+func (c *Controller) GetPayments(ctx context.Context, storeID string, page entity.Page) ([]entity.Payment, entity.PageInfo, error) {
+	if c.GetPaymentsFunc == nil {
+		return []entity.Payment{}, entity.PageInfo{}, nil
+	}
+	return c.GetPaymentsFunc(ctx, storeID, page)
+}
+
+func (c *Controller) SearchPayment(ctx context.Context, filter entity.PaymentFilter, page entity.Page) ([]entity.Payment, entity.PageInfo, error) {
+	if c.SearchPaymentFunc == nil {
+		return []entity.Payment{}, entity.PageInfo{}, nil
+	}
+	return c.SearchPaymentFunc(ctx, filter, page)
+}
+
+func (c *Controller) ExportRequests(ctx context.Context, storeID string, filter entity.RequestFilter, write func([]entity.Request) error) error {
+	if c.ExportRequestsFunc == nil {
+		return write([]entity.Request{})
+	}
+	return c.ExportRequestsFunc(ctx, storeID, filter, write)
+}
+
+func (c *Controller) ExportPayments(ctx context.Context, storeID string, filter entity.PaymentFilter, write func([]entity.Payment) error) error {
+	if c.ExportPaymentsFunc == nil {
+		return write([]entity.Payment{})
+	}
+	return c.ExportPaymentsFunc(ctx, storeID, filter, write)
+}
+
+func (c *Controller) SalesReport(ctx context.Context, storeID string, filter entity.ReportFilter) (*entity.SalesReport, error) {
+	if c.SalesReportFunc == nil {
+		return &entity.SalesReport{Periods: []entity.SalesPeriod{}}, nil
+	}
+	return c.SalesReportFunc(ctx, storeID, filter)
+}
+
+func (c *Controller) GMV(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.GMVPeriod, error) {
+	if c.GMVFunc == nil {
+		return []entity.GMVPeriod{}, nil
+	}
+	return c.GMVFunc(ctx, filter)
+}
+
+func (c *Controller) TopStores(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.StoreSales, error) {
+	if c.TopStoresFunc == nil {
+		return []entity.StoreSales{}, nil
+	}
+	return c.TopStoresFunc(ctx, filter)
+}
+
+func (c *Controller) TopCategories(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.CategorySales, error) {
+	if c.TopCategoriesFunc == nil {
+		return []entity.CategorySales{}, nil
+	}
+	return c.TopCategoriesFunc(ctx, filter)
+}
+
+func (c *Controller) Checkouts(ctx context.Context, filter entity.AnalyticsFilter) (*entity.CheckoutStats, error) {
+	if c.CheckoutsFunc == nil {
+		return &entity.CheckoutStats{}, nil
+	}
+	return c.CheckoutsFunc(ctx, filter)
+}
+
+func (c *Controller) Refunds(ctx context.Context, filter entity.AnalyticsFilter) (*entity.RefundStats, error) {
+	if c.RefundsFunc == nil {
+		return &entity.RefundStats{}, nil
+	}
+	return c.RefundsFunc(ctx, filter)
+}
@@ -0,0 +1,35 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"github.com/restore/shop/entity"
+)
+
+// ErrUnsupported is returned by the reports and analytics, which are only
+// aggregated in SQL. Test them on a repository over fixture.DB.
+var ErrUnsupported = errors.New("reports and analytics are not supported by the fake repository")
+
+func (r *Repository) SalesReport(ctx context.Context, filter entity.ReportFilter) (*entity.SalesReport, error) {
+	return nil, ErrUnsupported
+}
+
+func (r *Repository) GMV(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.GMVPeriod, error) {
+	return nil, ErrUnsupported
+}
+
+func (r *Repository) TopStores(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.StoreSales, error) {
+	return nil, ErrUnsupported
+}
+
+func (r *Repository) TopCategories(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.CategorySales, error) {
+	return nil, ErrUnsupported
+}
+
+func (r *Repository) Checkouts(ctx context.Context, filter entity.AnalyticsFilter) (*entity.CheckoutStats, error) {
+	return nil, ErrUnsupported
+}
+
+func (r *Repository) Refunds(ctx context.Context, filter entity.AnalyticsFilter) (*entity.RefundStats, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,378 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/restore/shop/entity"
+	"gorm.io/gorm"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is the error of the database repository for missing records.
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// Repository is an in-memory repository of Requests and Payments. Err, when
+// set, is returned by every method.
+type Repository struct {
+	Err error
+
+	mu       sync.Mutex
+	requests []entity.Request
+	payments []entity.Payment
+}
+
+func NewRepository() *Repository {
+	return &Repository{}
+}
+
+// Requests returns a copy of every stored Request.
+func (r *Repository) Requests() []entity.Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]entity.Request{}, r.requests...)
+}
+
+// Payments returns a copy of every stored Payment.
+func (r *Repository) Payments() []entity.Payment {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]entity.Payment{}, r.payments...)
+}
+
+func (r *Repository) CreateRequest(ctx context.Context, request *entity.Request) error {
+	if r.Err != nil {
+		return r.Err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request.ID = len(r.requests) + 1
+	request.Version = 1
+	if request.CreatedAt.IsZero() {
+		request.CreatedAt = time.Now()
+	}
+	r.requests = append(r.requests, *request)
+	return nil
+}
+
+func (r *Repository) UpdateRequest(ctx context.Context, id int, request *entity.Request) error {
+	if r.Err != nil {
+		return r.Err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.requests {
+		if r.requests[i].ID == id {
+			if request.Version != 0 && request.Version != r.requests[i].Version {
+				return entity.ErrVersionConflict
+			}
+			r.requests[i].Status = request.Status
+			r.requests[i].Track = request.Track
+			r.requests[i].Version++
+			request.Version = r.requests[i].Version
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *Repository) UpdateRequests(ctx context.Context, requests []entity.Request) error {
+	if r.Err != nil {
+		return r.Err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	positions := []int{}
+	for _, request := range requests {
+		position := -1
+		for i := range r.requests {
+			if r.requests[i].ID == request.ID {
+				position = i
+			}
+		}
+		if position < 0 {
+			return ErrNotFound
+		}
+		if r.requests[position].Version != request.Version {
+			return fmt.Errorf("request %d: %w", request.ID, entity.ErrVersionConflict)
+		}
+		positions = append(positions, position)
+	}
+
+	for i, position := range positions {
+		r.requests[position].Status = requests[i].Status
+		r.requests[position].Track = requests[i].Track
+		r.requests[position].Version++
+		requests[i].Version = r.requests[position].Version
+	}
+	return nil
+}
+
+func (r *Repository) ConfirmRequests(ctx context.Context, paymentID string) error {
+	if r.Err != nil {
+		return r.Err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.requests {
+		if r.requests[i].PaymentID == paymentID {
+			r.requests[i].Status = "preparing"
+			r.requests[i].Version++
+		}
+	}
+	return nil
+}
+
+func (r *Repository) GetRequestByPayment(ctx context.Context, paymentID string) ([]entity.Request, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := []entity.Request{}
+	for _, req := range r.requests {
+		if req.PaymentID == paymentID {
+			result = append(result, req)
+		}
+	}
+	return result, nil
+}
+
+func (r *Repository) GetRequests(ctx context.Context, ids []int) ([]entity.Request, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := []entity.Request{}
+	for _, req := range r.requests {
+		for _, id := range ids {
+			if req.ID == id {
+				result = append(result, req)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func (r *Repository) GetRequest(ctx context.Context, id int) (*entity.Request, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, req := range r.requests {
+		if req.ID == id {
+			return &req, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *Repository) SearchRequest(ctx context.Context, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error) {
+	if r.Err != nil {
+		return nil, entity.PageInfo{}, r.Err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := []entity.Request{}
+	for _, req := range r.requests {
+		if matchRequest(req, filter) {
+			result = append(result, req)
+		}
+	}
+	return paginate(result, page, func(req entity.Request) (time.Time, int) {
+		return req.CreatedAt, req.ID
+	})
+}
+
+func (r *Repository) ListRequests(ctx context.Context, filter entity.RequestFilter, page entity.Page) ([]entity.Request, string, error) {
+	result, info, err := r.SearchRequest(ctx, filter, page)
+	return result, info.NextCursor, err
+}
+
+func (r *Repository) CreatePayment(ctx context.Context, payment *entity.Payment) (int, error) {
+	if r.Err != nil {
+		return 0, r.Err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment.ID = len(r.payments) + 1
+	payment.Version = 1
+	if payment.CreatedAt.IsZero() {
+		payment.CreatedAt = time.Now()
+	}
+	r.payments = append(r.payments, *payment)
+	return payment.ID, nil
+}
+
+func (r *Repository) UpdatePayment(ctx context.Context, id int, payment *entity.Payment) error {
+	if r.Err != nil {
+		return r.Err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.payments {
+		if r.payments[i].ID == id {
+			if payment.Version != 0 && payment.Version != r.payments[i].Version {
+				return entity.ErrVersionConflict
+			}
+			r.payments[i].Status = payment.Status
+			r.payments[i].Version++
+			payment.Version = r.payments[i].Version
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *Repository) GetPayment(ctx context.Context, id int) (*entity.Payment, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, payment := range r.payments {
+		if payment.ID == id {
+			return &payment, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *Repository) GetPayments(ctx context.Context, id int, page entity.Page) ([]entity.Payment, entity.PageInfo, error) {
+	return r.SearchPayment(ctx, entity.PaymentFilter{StoreID: id}, page)
+}
+
+func (r *Repository) SearchPayment(ctx context.Context, filter entity.PaymentFilter, page entity.Page) ([]entity.Payment, entity.PageInfo, error) {
+	if r.Err != nil {
+		return nil, entity.PageInfo{}, r.Err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := []entity.Payment{}
+	for _, payment := range r.payments {
+		if matchPayment(payment, filter) {
+			result = append(result, payment)
+		}
+	}
+	return paginate(result, page, func(payment entity.Payment) (time.Time, int) {
+		return payment.CreatedAt, payment.ID
+	})
+}
+
+func (r *Repository) ListPayments(ctx context.Context, filter entity.PaymentFilter, page entity.Page) ([]entity.Payment, string, error) {
+	result, info, err := r.SearchPayment(ctx, filter, page)
+	return result, info.NextCursor, err
+}
+
+func matchRequest(req entity.Request, filter entity.RequestFilter) bool {
+	switch {
+	case filter.StoreID != 0 && req.StoreID != filter.StoreID,
+		filter.UserID != 0 && req.UserID != filter.UserID,
+		filter.ProductID != 0 && req.ProductID != filter.ProductID,
+		filter.PaymentID != "" && req.PaymentID != filter.PaymentID,
+		!filter.Unpaid && req.Status == entity.StatusCreated,
+		len(filter.Status) > 0 && !contains(filter.Status, req.Status),
+		filter.MinPrice != nil && req.Price < *filter.MinPrice,
+		filter.MaxPrice != nil && req.Price > *filter.MaxPrice,
+		!filter.InitialDate.IsZero() && !req.CreatedAt.After(filter.InitialDate),
+		!filter.EndDate.IsZero() && !req.CreatedAt.Before(filter.EndDate):
+		return false
+	}
+	return true
+}
+
+func matchPayment(payment entity.Payment, filter entity.PaymentFilter) bool {
+	switch {
+	case filter.StoreID != 0 && payment.StoreID != filter.StoreID,
+		filter.ProductID != 0 && payment.ProductID != filter.ProductID,
+		len(filter.Status) > 0 && !contains(filter.Status, payment.Status),
+		filter.MinTotal != nil && payment.Total < *filter.MinTotal,
+		filter.MaxTotal != nil && payment.Total > *filter.MaxTotal,
+		!filter.InitialDate.IsZero() && !payment.CreatedAt.After(filter.InitialDate),
+		!filter.EndDate.IsZero() && !payment.CreatedAt.Before(filter.EndDate):
+		return false
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// paginate sorts items the same way the repository does and returns the page
+// selected by page. Cursors are offsets into the sorted items.
+func paginate[T any](items []T, page entity.Page, key func(T) (time.Time, int)) ([]T, entity.PageInfo, error) {
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, idi := key(items[i])
+		tj, idj := key(items[j])
+		less := ti.Before(tj) || ti.Equal(tj) && idi < idj
+		if page.Sort == entity.SortAsc {
+			return less
+		}
+		return !less && (!ti.Equal(tj) || idi != idj)
+	})
+
+	info := entity.PageInfo{Total: int64(len(items))}
+
+	offset := 0
+	if page.Cursor != "" {
+		var err error
+		offset, err = strconv.Atoi(page.Cursor)
+		if err != nil || offset < 0 {
+			return nil, entity.PageInfo{}, errors.New("invalid cursor")
+		}
+	}
+	if offset > len(items) {
+		offset = len(items)
+	}
+
+	limit := page.Limit
+	if limit <= 0 {
+		limit = entity.DefaultLimit
+	}
+
+	end := offset + limit
+	if end < len(items) {
+		info.NextCursor = strconv.Itoa(end)
+	} else {
+		end = len(items)
+	}
+	return items[offset:end], info, nil
+}
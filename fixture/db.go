@@ -0,0 +1,46 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"github.com/restore/shop/migrations"
+	"github.com/restore/shop/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"sync/atomic"
+	"testing"
+)
+
+var databases atomic.Int64
+
+// DB opens an in-process SQLite database with every migration applied. The
+// database is private to tb and closed when tb finishes.
+func DB(tb testing.TB) *gorm.DB {
+	tb.Helper()
+
+	db, err := repository.Init(&repository.Config{
+		Driver:   repository.DriverSQLite,
+		Database: fmt.Sprintf("file:fixture%d?mode=memory&cache=shared", databases.Add(1)),
+	}, zap.NewNop())
+	if err != nil {
+		tb.Fatalf("error opening database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		tb.Fatalf("error opening database: %v", err)
+	}
+	tb.Cleanup(func() {
+		sqlDB.Close()
+	})
+
+	runner, err := migrations.NewRunner(sqlDB, repository.DriverSQLite)
+	if err != nil {
+		tb.Fatalf("error loading migrations: %v", err)
+	}
+	_, err = runner.Up(context.Background())
+	if err != nil {
+		tb.Fatalf("error applying migrations: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,111 @@
+// Package fixture provides entities, upstream responses and wired services
+// for tests of the shop and of services calling it.
+package fixture
+
+import (
+	"context"
+	productpb "github.com/ReStorePUC/protobucket/product"
+	pb "github.com/ReStorePUC/protobucket/user"
+	"github.com/restore/shop/config"
+	"github.com/restore/shop/controller"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/fake"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const (
+	AdminEmail = "admin@restore.test"
+	UserEmail  = "user@restore.test"
+
+	AdminID = "1"
+	UserID  = "2"
+)
+
+// CreatedAt is the creation date of every fixture entity.
+var CreatedAt = time.Date(2023, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// Context returns a context of a call made by email.
+func Context(email string) context.Context {
+	return context.WithValue(context.Background(), config.EmailHeader, email)
+}
+
+// Users returns the admin and the regular user, by email.
+func Users() map[string]*pb.GetUserResponse {
+	return map[string]*pb.GetUserResponse{
+		AdminEmail: {Id: AdminID, IsAdmin: true},
+		UserEmail:  {Id: UserID},
+	}
+}
+
+// Product returns an available product of store.
+func Product(id, storeID int) *productpb.GetProductResponse {
+	return &productpb.GetProductResponse{
+		Id:          int32(id),
+		Name:        "Product " + strconv.Itoa(id),
+		Description: "Description of product " + strconv.Itoa(id),
+		Categories:  "clothes",
+		Size:        "M",
+		Price:       100,
+		Tax:         10,
+		Available:   true,
+		StoreId:     int32(storeID),
+		Images: []*productpb.Image{
+			{Id: int32(id), ImagePath: "images/" + strconv.Itoa(id) + ".png", ProductId: int32(id)},
+		},
+	}
+}
+
+// Request returns a Request of product, bought by user from store.
+func Request(storeID, productID, userID int, status string) entity.Request {
+	return entity.Request{
+		PaymentID: "1",
+		Price:     100,
+		Tax:       10,
+		Status:    status,
+		CreatedAt: CreatedAt,
+		StoreID:   storeID,
+		ProductID: productID,
+		UserID:    userID,
+	}
+}
+
+// Payment returns a Payment of product, made to store.
+func Payment(storeID, productID int, total float64, status string) entity.Payment {
+	return entity.Payment{
+		Total:     total,
+		PIX:       "pix",
+		Status:    status,
+		CreatedAt: CreatedAt,
+		StoreID:   storeID,
+		ProductID: productID,
+	}
+}
+
+// Shop is a controller wired to fake upstreams and an in-memory repository.
+type Shop struct {
+	*controller.Shop
+
+	Repository *fake.Repository
+	Users      *fake.UserClient
+	Products   *fake.ProductClient
+	Payments   *fake.PaymentClient
+}
+
+// NewShop builds a Shop knowing the fixture Users and no products.
+func NewShop() *Shop {
+	repo := fake.NewRepository()
+	users := fake.NewUserClient()
+	users.Users = Users()
+	products := fake.NewProductClient()
+	payments := fake.NewPaymentClient()
+
+	return &Shop{
+		Shop:       controller.NewShop(repo, users, products, payments, zap.NewNop()),
+		Repository: repo,
+		Users:      users,
+		Products:   products,
+		Payments:   payments,
+	}
+}
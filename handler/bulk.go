@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/restore/shop/entity"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const bulkFileField = "file"
+
+// bulkUpdates reads the updates of a bulk request, from a JSON body, a CSV
+// body or a CSV file uploaded in the file field of a multipart form.
+func bulkUpdates(c *gin.Context) ([]entity.StatusUpdate, error) {
+	switch c.ContentType() {
+	case "text/csv":
+		return csvUpdates(c.Request.Body)
+	case gin.MIMEMultipartPOSTForm:
+		header, err := c.FormFile(bulkFileField)
+		if err != nil {
+			return nil, err
+		}
+		f, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return csvUpdates(f)
+	}
+
+	var bulk entity.BulkUpdate
+	if err := c.ShouldBindJSON(&bulk); err != nil {
+		return nil, err
+	}
+	return bulk.Items, nil
+}
+
+// csvUpdates reads updates from CSV with a header row naming the id, status,
+// track and version columns. Only id and status are required.
+func csvUpdates(r io.Reader) ([]entity.StatusUpdate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("empty CSV")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"id", "status"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV has no %s column", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	updates := []entity.StatusUpdate{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(updates) == entity.MaxBulkUpdates {
+			return nil, fmt.Errorf("CSV has more than %d updates", entity.MaxBulkUpdates)
+		}
+
+		update := entity.StatusUpdate{
+			Status: field(record, "status"),
+			Track:  field(record, "track"),
+		}
+		update.ID, err = strconv.Atoi(field(record, "id"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid id on line %d", line)
+		}
+		if version := field(record, "version"); version != "" {
+			update.Version, err = strconv.Atoi(version)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version on line %d", line)
+			}
+		}
+		updates = append(updates, update)
+	}
+	return updates, nil
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/restore/shop/entity"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	etagHeader    = "ETag"
+	ifMatchHeader = "If-Match"
+)
+
+// ifMatch reads the version the client expects to update from the If-Match
+// header. A missing header or "*" returns zero, matching any version.
+func ifMatch(c *gin.Context) (int, error) {
+	value := strings.TrimSpace(c.GetHeader(ifMatchHeader))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	value = strings.Trim(strings.TrimPrefix(value, "W/"), `"`)
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, errors.New("invalid If-Match header")
+	}
+	return version, nil
+}
+
+// setETag writes the version of the returned record.
+func setETag(c *gin.Context, version int) {
+	c.Header(etagHeader, `"`+strconv.Itoa(version)+`"`)
+}
+
+// updateStatus returns the status code of a failed update.
+func updateStatus(err error) int {
+	if errors.Is(err, entity.ErrVersionConflict) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
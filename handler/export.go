@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/restore/shop/config"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/export"
+	"net/http"
+	"time"
+)
+
+var (
+	requestColumns = []interface{}{
+		"id", "created_at", "payment_id", "status", "track", "store_id", "user_id",
+		"product_id", "product_name", "price", "tax", "total", "warning",
+	}
+	paymentColumns = []interface{}{
+		"id", "created_at", "status", "pix", "store_id", "product_id", "product_name", "total",
+	}
+)
+
+// ExportRequests streams the Requests of a store as CSV or XLSX.
+func (s *Shop) ExportRequests(c *gin.Context) {
+	ctx := context.WithValue(c.Request.Context(), config.EmailHeader, c.GetHeader(config.EmailHeader))
+
+	storeID := c.Param("storeID")
+	if storeID == "" {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			"invalid ID",
+		})
+		return
+	}
+
+	stream, err := newExportStream(c, "requests-"+storeID, requestColumns)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	filter, err := requestFilterQuery(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	err = s.controller.ExportRequests(ctx, storeID, filter, func(requests []entity.Request) error {
+		for _, req := range requests {
+			name := ""
+			if req.Product != nil {
+				name = req.Product.Name
+			}
+			err := stream.writeRow(
+				req.ID, req.CreatedAt, req.PaymentID, req.Status, req.Track, req.StoreID, req.UserID,
+				req.ProductID, name, req.Price, req.Tax, req.Price+req.Tax, req.Warning,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return stream.flush()
+	})
+	stream.close(err)
+}
+
+// ExportPayments streams the Payments of a store as CSV or XLSX.
+func (s *Shop) ExportPayments(c *gin.Context) {
+	ctx := context.WithValue(c.Request.Context(), config.EmailHeader, c.GetHeader(config.EmailHeader))
+
+	storeID := c.Param("storeID")
+	if storeID == "" {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			"invalid ID",
+		})
+		return
+	}
+
+	stream, err := newExportStream(c, "payments-"+storeID, paymentColumns)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	filter, err := paymentFilterQuery(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	err = s.controller.ExportPayments(ctx, storeID, filter, func(payments []entity.Payment) error {
+		for _, payment := range payments {
+			name := ""
+			if payment.Product != nil {
+				name = payment.Product.Name
+			}
+			err := stream.writeRow(
+				payment.ID, payment.CreatedAt, payment.Status, payment.PIX, payment.StoreID,
+				payment.ProductID, name, payment.Total,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return stream.flush()
+	})
+	stream.close(err)
+}
+
+// exportStream writes an export to the response. The response starts with
+// the first row, so errors raised before it are still answered with JSON.
+type exportStream struct {
+	c       *gin.Context
+	format  string
+	name    string
+	columns []interface{}
+	w       export.Writer
+}
+
+func newExportStream(c *gin.Context, name string, columns []interface{}) (*exportStream, error) {
+	format := c.DefaultQuery("format", export.FormatCSV)
+	if format != export.FormatCSV && format != export.FormatXLSX {
+		return nil, errors.New("format must be csv or xlsx")
+	}
+
+	// Exports outlast the write timeout of the server, so they are only cut
+	// short by the client going away.
+	err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, err
+	}
+
+	return &exportStream{
+		c:       c,
+		format:  format,
+		name:    name,
+		columns: columns,
+	}, nil
+}
+
+func (e *exportStream) start() error {
+	if e.w != nil {
+		return nil
+	}
+
+	e.c.Header("Content-Type", export.ContentType(e.format))
+	e.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, e.name, e.format))
+	e.c.Status(http.StatusOK)
+
+	w, err := export.New(e.format, e.c.Writer, e.name)
+	if err != nil {
+		return err
+	}
+	e.w = w
+	return e.w.WriteRow(e.columns...)
+}
+
+func (e *exportStream) writeRow(cells ...interface{}) error {
+	err := e.start()
+	if err != nil {
+		return err
+	}
+	return e.w.WriteRow(cells...)
+}
+
+// flush sends the rows written so far to the client.
+func (e *exportStream) flush() error {
+	err := e.start()
+	if err != nil {
+		return err
+	}
+	e.c.Writer.Flush()
+	return nil
+}
+
+// close ends the export, or answers err when nothing was sent yet. Errors
+// after the first row can only cut the export short.
+func (e *exportStream) close(err error) {
+	if err != nil {
+		if e.w == nil {
+			e.c.IndentedJSON(http.StatusBadRequest, struct {
+				Error string
+			}{
+				err.Error(),
+			})
+			return
+		}
+		e.c.Error(err)
+		return
+	}
+
+	err = e.start()
+	if err == nil {
+		err = e.w.Close()
+	}
+	if err != nil {
+		e.c.Error(err)
+	}
+}
@@ -0,0 +1,67 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"github.com/gin-gonic/gin"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/fake"
+	"github.com/restore/shop/fixture"
+	"github.com/restore/shop/handler"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExportRequestsOutlastsWriteTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c := &fake.Controller{
+		ExportRequestsFunc: func(ctx context.Context, storeID string, filter entity.RequestFilter, write func([]entity.Request) error) error {
+			for i := 1; i <= 3; i++ {
+				// Each page takes longer than the write timeout.
+				time.Sleep(100 * time.Millisecond)
+
+				req := fixture.Request(1, 10, 2, "preparing")
+				req.ID = i
+				req.Track = "=1+1"
+				err := write([]entity.Request{req})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	router := gin.New()
+	router.GET("/private/request/export/:storeID", handler.NewShop(c).ExportRequests)
+
+	srv := httptest.NewUnstartedServer(router)
+	srv.Config.WriteTimeout = 50 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/private/request/export/1")
+	if err != nil {
+		t.Fatalf("error exporting: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("error reading export: %v", err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		t.Fatalf("error parsing export %q: %v", body, err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("rows = %d, want a header and 3 requests", len(rows))
+	}
+	if track := rows[1][4]; track != "'=1+1" {
+		t.Errorf("track = %q, want it escaped", track)
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/restore/shop/client"
+	"google.golang.org/grpc/connectivity"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+
+	pingTimeout = 2 * time.Second
+)
+
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Connection is a gRPC client connection.
+type Connection interface {
+	GetState() connectivity.State
+	Connect()
+}
+
+// Dependency represents data about the status of a dependency.
+type Dependency struct {
+	Status string `json:"status"`
+	State  string `json:"state,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Health struct {
+	db       pinger
+	conns    map[string]Connection
+	draining atomic.Bool
+}
+
+func NewHealth(db pinger, conns map[string]Connection) *Health {
+	return &Health{
+		db:    db,
+		conns: conns,
+	}
+}
+
+// Drain makes the service report itself as not ready, during shutdown.
+func (h *Health) Drain() {
+	h.draining.Store(true)
+}
+
+// Healthz reports whether the service is alive.
+func (h *Health) Healthz(c *gin.Context) {
+	c.IndentedJSON(http.StatusOK, struct {
+		Status string `json:"status"`
+	}{
+		statusOK,
+	})
+}
+
+// Readyz reports whether the service can serve requests, and the status of
+// its dependencies. Only the database and draining make it not ready: the
+// upstreams have fallbacks, and one of them going down must not take every
+// replica out of the load balancer.
+func (h *Health) Readyz(c *gin.Context) {
+	ready := !h.draining.Load()
+	deps := map[string]Dependency{}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), pingTimeout)
+	defer cancel()
+
+	err := h.db.PingContext(ctx)
+	if err != nil {
+		ready = false
+		deps["database"] = Dependency{Status: statusUnavailable, Error: err.Error()}
+	} else {
+		deps["database"] = Dependency{Status: statusOK}
+	}
+
+	for name, conn := range h.conns {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			deps[name] = Dependency{Status: statusOK, State: state.String()}
+		case connectivity.Idle:
+			// Idle connections reconnect on the next call.
+			conn.Connect()
+			deps[name] = Dependency{Status: statusOK, State: state.String()}
+		default:
+			deps[name] = Dependency{Status: statusUnavailable, State: state.String()}
+		}
+	}
+
+	status, code := statusOK, http.StatusOK
+	if !ready {
+		status, code = statusUnavailable, http.StatusServiceUnavailable
+	}
+
+	c.IndentedJSON(code, struct {
+		Status       string                `json:"status"`
+		Dependencies map[string]Dependency `json:"dependencies"`
+		Breakers     []client.BreakerStats `json:"breakers"`
+	}{
+		status,
+		deps,
+		client.Breakers(),
+	})
+}
@@ -0,0 +1,79 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/restore/shop/handler"
+	"google.golang.org/grpc/connectivity"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type pinger struct {
+	err error
+}
+
+func (p pinger) PingContext(context.Context) error {
+	return p.err
+}
+
+type connection struct {
+	state connectivity.State
+}
+
+func (c connection) GetState() connectivity.State {
+	return c.state
+}
+
+func (c connection) Connect() {}
+
+func TestReadyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		db       error
+		product  connectivity.State
+		drain    bool
+		want     int
+		wantProd string
+	}{
+		{"ready", nil, connectivity.Ready, false, http.StatusOK, "ok"},
+		{"upstream down", nil, connectivity.TransientFailure, false, http.StatusOK, "unavailable"},
+		{"upstream connecting", nil, connectivity.Connecting, false, http.StatusOK, "unavailable"},
+		{"database down", errors.New("connection refused"), connectivity.Ready, false, http.StatusServiceUnavailable, "ok"},
+		{"draining", nil, connectivity.Ready, true, http.StatusServiceUnavailable, "ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.NewHealth(pinger{tt.db}, map[string]handler.Connection{
+				"product": connection{tt.product},
+			})
+			if tt.drain {
+				h.Drain()
+			}
+			router := gin.New()
+			router.GET("/readyz", h.Readyz)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != tt.want {
+				t.Errorf("code = %d, want %d", w.Code, tt.want)
+			}
+			var body struct {
+				Dependencies map[string]handler.Dependency `json:"dependencies"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &body)
+			if err != nil {
+				t.Fatalf("error decoding %s: %v", w.Body, err)
+			}
+			if status := body.Dependencies["product"].Status; status != tt.wantProd {
+				t.Errorf("product = %q, want %q", status, tt.wantProd)
+			}
+		})
+	}
+}
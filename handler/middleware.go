@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/restore/shop/logger"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// MaxBodySize limits the size of request bodies to n bytes.
+func MaxBodySize(n int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, n)
+		c.Next()
+	}
+}
+
+// RequestID propagates the request ID header, or a new one, into the
+// request context and the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(logger.RequestIDHeader)
+		if id == "" {
+			id = logger.NewRequestID()
+		}
+
+		c.Header(logger.RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// Logger logs every request handled by the router.
+func Logger(log *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		log := logger.For(c.Request.Context(), log)
+		switch {
+		case c.Writer.Status() >= http.StatusInternalServerError:
+			log.Error("request", fields...)
+		case c.Writer.Status() >= http.StatusBadRequest:
+			log.Warn("request", fields...)
+		default:
+			log.Info("request", fields...)
+		}
+	}
+}
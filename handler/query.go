@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/restore/shop/entity"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	totalCountHeader = "X-Total-Count"
+	nextCursorHeader = "X-Next-Cursor"
+)
+
+// pageQuery reads the pagination parameters of a search.
+func pageQuery(c *gin.Context) (entity.Page, error) {
+	limit, err := intQuery(c, "limit")
+	if err != nil {
+		return entity.Page{}, err
+	}
+
+	return entity.Page{
+		Limit:  limit,
+		Cursor: c.Query("cursor"),
+		Sort:   c.Query("sort"),
+	}, nil
+}
+
+// setPageHeaders writes the pagination result of a search.
+func setPageHeaders(c *gin.Context, info entity.PageInfo) {
+	c.Header(totalCountHeader, strconv.FormatInt(info.Total, 10))
+	if info.NextCursor != "" {
+		c.Header(nextCursorHeader, info.NextCursor)
+	}
+}
+
+// paymentFilterQuery reads the criteria of a payment search.
+func paymentFilterQuery(c *gin.Context) (entity.PaymentFilter, error) {
+	var (
+		filter entity.PaymentFilter
+		err    error
+	)
+
+	filter.StoreID, err = intQuery(c, "storeID")
+	if err != nil {
+		return filter, err
+	}
+	filter.ProductID, err = intQuery(c, "productID")
+	if err != nil {
+		return filter, err
+	}
+	filter.MinTotal, err = floatQuery(c, "minTotal")
+	if err != nil {
+		return filter, err
+	}
+	filter.MaxTotal, err = floatQuery(c, "maxTotal")
+	if err != nil {
+		return filter, err
+	}
+	filter.InitialDate, err = timeQuery(c, "initialDate")
+	if err != nil {
+		return filter, err
+	}
+	filter.EndDate, err = timeQuery(c, "endDate")
+	if err != nil {
+		return filter, err
+	}
+	filter.Status = listQuery(c, "status")
+
+	return filter, nil
+}
+
+// requestFilterQuery reads the criteria of a request search.
+func requestFilterQuery(c *gin.Context) (entity.RequestFilter, error) {
+	var (
+		filter entity.RequestFilter
+		err    error
+	)
+
+	filter.StoreID, err = intQuery(c, "storeID")
+	if err != nil {
+		return filter, err
+	}
+	filter.UserID, err = intQuery(c, "userID")
+	if err != nil {
+		return filter, err
+	}
+	filter.ProductID, err = intQuery(c, "productID")
+	if err != nil {
+		return filter, err
+	}
+	filter.MinPrice, err = floatQuery(c, "minPrice")
+	if err != nil {
+		return filter, err
+	}
+	filter.MaxPrice, err = floatQuery(c, "maxPrice")
+	if err != nil {
+		return filter, err
+	}
+	filter.InitialDate, err = timeQuery(c, "initialDate")
+	if err != nil {
+		return filter, err
+	}
+	filter.EndDate, err = timeQuery(c, "endDate")
+	if err != nil {
+		return filter, err
+	}
+	filter.PaymentID = c.Query("paymentID")
+	filter.Status = listQuery(c, "status")
+	filter.Refresh = c.Query("refresh") == "true"
+
+	return filter, nil
+}
+
+// reportFilterQuery reads the criteria of a report.
+func reportFilterQuery(c *gin.Context) (entity.ReportFilter, error) {
+	var (
+		filter entity.ReportFilter
+		err    error
+	)
+
+	filter.Period = c.Query("period")
+	filter.InitialDate, err = timeQuery(c, "initialDate")
+	if err != nil {
+		return filter, err
+	}
+	filter.EndDate, err = timeQuery(c, "endDate")
+	if err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// analyticsFilterQuery reads the criteria of the platform analytics.
+func analyticsFilterQuery(c *gin.Context) (entity.AnalyticsFilter, error) {
+	var (
+		filter entity.AnalyticsFilter
+		err    error
+	)
+
+	filter.Period = c.Query("period")
+	filter.InitialDate, err = timeQuery(c, "initialDate")
+	if err != nil {
+		return filter, err
+	}
+	filter.EndDate, err = timeQuery(c, "endDate")
+	if err != nil {
+		return filter, err
+	}
+	filter.Limit, err = intQuery(c, "limit")
+	if err != nil {
+		return filter, err
+	}
+	filter.AbandonedAfter, err = durationQuery(c, "abandonedAfter")
+	if err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// listQuery reads a parameter given either repeated or comma separated.
+func listQuery(c *gin.Context, key string) []string {
+	var result []string
+	for _, value := range c.QueryArray(key) {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+func intQuery(c *gin.Context, key string) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.New("invalid " + key)
+	}
+	return result, nil
+}
+
+func floatQuery(c *gin.Context, key string) (*float64, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, errors.New("invalid " + key)
+	}
+	return &result, nil
+}
+
+func timeQuery(c *gin.Context, key string) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	result, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.New("invalid " + key)
+	}
+	return result, nil
+}
+
+func durationQuery(c *gin.Context, key string) (time.Duration, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	result, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.New("invalid " + key)
+	}
+	return result, nil
+}
@@ -6,19 +6,33 @@ import (
 	"github.com/restore/shop/config"
 	"github.com/restore/shop/entity"
 	"net/http"
+	"strconv"
 )
 
 type controller interface {
 	CreateRequest(ctx context.Context, request *entity.Create) (string, error)
 	UpdateRequest(ctx context.Context, id string, request *entity.Request) error
+	GetRequest(ctx context.Context, id string) (*entity.Request, error)
+	BulkUpdateRequests(ctx context.Context, updates []entity.StatusUpdate, atomic bool) ([]entity.StatusUpdateResult, error)
 	ConfirmRequest(ctx context.Context, paymentID string) error
-	SearchRequest(ctx context.Context, storeID, status, initialDate, endDate string) ([]entity.Request, error)
-	SearchProfileRequest(ctx context.Context, profileID, status, initialDate, endDate string) ([]entity.Request, error)
+	SearchRequest(ctx context.Context, storeID string, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error)
+	SearchProfileRequest(ctx context.Context, profileID string, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error)
+	SearchAllRequest(ctx context.Context, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error)
+	ExportRequests(ctx context.Context, storeID string, filter entity.RequestFilter, write func([]entity.Request) error) error
 
 	CreatePayment(ctx context.Context, payment *entity.Payment) (int, error)
 	UpdatePayment(ctx context.Context, id string, payment *entity.Payment) error
-	GetPayments(ctx context.Context, storeID string) ([]entity.Payment, error)
-	SearchPayment(ctx context.Context, status, initialDate, endDate string) ([]entity.Payment, error)
+	GetPayment(ctx context.Context, id string) (*entity.Payment, error)
+	GetPayments(ctx context.Context, storeID string, page entity.Page) ([]entity.Payment, entity.PageInfo, error)
+	SearchPayment(ctx context.Context, filter entity.PaymentFilter, page entity.Page) ([]entity.Payment, entity.PageInfo, error)
+	ExportPayments(ctx context.Context, storeID string, filter entity.PaymentFilter, write func([]entity.Payment) error) error
+
+	SalesReport(ctx context.Context, storeID string, filter entity.ReportFilter) (*entity.SalesReport, error)
+	GMV(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.GMVPeriod, error)
+	TopStores(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.StoreSales, error)
+	TopCategories(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.CategorySales, error)
+	Checkouts(ctx context.Context, filter entity.AnalyticsFilter) (*entity.CheckoutStats, error)
+	Refunds(ctx context.Context, filter entity.AnalyticsFilter) (*entity.RefundStats, error)
 }
 
 type Shop struct {
@@ -76,6 +90,16 @@ func (s *Shop) UpdateRequest(c *gin.Context) {
 		return
 	}
 
+	version, err := ifMatch(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
 	var request entity.Request
 	if err := c.BindJSON(&request); err != nil {
 		c.IndentedJSON(http.StatusBadRequest, struct {
@@ -85,10 +109,11 @@ func (s *Shop) UpdateRequest(c *gin.Context) {
 		})
 		return
 	}
+	request.Version = version
 
-	err := s.controller.UpdateRequest(ctx, id, &request)
+	err = s.controller.UpdateRequest(ctx, id, &request)
 	if err != nil {
-		c.IndentedJSON(http.StatusBadRequest, struct {
+		c.IndentedJSON(updateStatus(err), struct {
 			Error string
 		}{
 			err.Error(),
@@ -96,9 +121,94 @@ func (s *Shop) UpdateRequest(c *gin.Context) {
 		return
 	}
 
+	setETag(c, request.Version)
 	c.IndentedJSON(http.StatusOK, struct{}{})
 }
 
+// GetRequest gets a Request, with its version as the ETag to send back in
+// If-Match when updating it.
+func (s *Shop) GetRequest(c *gin.Context) {
+	ctx := context.WithValue(c.Request.Context(), config.EmailHeader, c.GetHeader(config.EmailHeader))
+
+	id := c.Param("id")
+	if id == "" {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			"invalid ID",
+		})
+		return
+	}
+
+	request, err := s.controller.GetRequest(ctx, id)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	setETag(c, request.Version)
+	c.IndentedJSON(http.StatusOK, request)
+}
+
+// BulkUpdateRequests updates the status and track of many Requests, from
+// JSON or CSV. With atomic=true either every update is applied or none is.
+func (s *Shop) BulkUpdateRequests(c *gin.Context) {
+	ctx := context.WithValue(c.Request.Context(), config.EmailHeader, c.GetHeader(config.EmailHeader))
+
+	atomic, err := strconv.ParseBool(c.DefaultQuery("atomic", "false"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			"invalid atomic",
+		})
+		return
+	}
+
+	updates, err := bulkUpdates(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	results, err := s.controller.BulkUpdateRequests(ctx, updates, atomic)
+	if err != nil {
+		c.IndentedJSON(updateStatus(err), struct {
+			Error   string
+			Results []entity.StatusUpdateResult `json:"results,omitempty"`
+		}{
+			err.Error(),
+			results,
+		})
+		return
+	}
+
+	applied := 0
+	for _, result := range results {
+		if result.Applied {
+			applied++
+		}
+	}
+
+	c.IndentedJSON(http.StatusOK, struct {
+		Applied int                         `json:"applied"`
+		Failed  int                         `json:"failed"`
+		Results []entity.StatusUpdateResult `json:"results"`
+	}{
+		applied,
+		len(results) - applied,
+		results,
+	})
+}
+
 // SearchRequest searches for Requests.
 func (s *Shop) SearchRequest(c *gin.Context) {
 	ctx := context.WithValue(c.Request.Context(), config.EmailHeader, c.GetHeader(config.EmailHeader))
@@ -113,13 +223,27 @@ func (s *Shop) SearchRequest(c *gin.Context) {
 		return
 	}
 
-	result, err := s.controller.SearchRequest(
-		ctx,
-		storeID,
-		c.Query("status"),
-		c.Query("initialDate"),
-		c.Query("endDate"),
-	)
+	filter, err := requestFilterQuery(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	page, err := pageQuery(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	result, info, err := s.controller.SearchRequest(ctx, storeID, filter, page)
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, struct {
 			Error string
@@ -129,6 +253,7 @@ func (s *Shop) SearchRequest(c *gin.Context) {
 		return
 	}
 
+	setPageHeaders(c, info)
 	c.IndentedJSON(http.StatusOK, result)
 }
 
@@ -146,13 +271,17 @@ func (s *Shop) SearchProfileRequest(c *gin.Context) {
 		return
 	}
 
-	result, err := s.controller.SearchProfileRequest(
-		ctx,
-		profileID,
-		c.Query("status"),
-		c.Query("initialDate"),
-		c.Query("endDate"),
-	)
+	filter, err := requestFilterQuery(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	page, err := pageQuery(c)
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, struct {
 			Error string
@@ -162,6 +291,55 @@ func (s *Shop) SearchProfileRequest(c *gin.Context) {
 		return
 	}
 
+	result, info, err := s.controller.SearchProfileRequest(ctx, profileID, filter, page)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	setPageHeaders(c, info)
+	c.IndentedJSON(http.StatusOK, result)
+}
+
+// SearchAllRequest searches for Requests of every store.
+func (s *Shop) SearchAllRequest(c *gin.Context) {
+	ctx := context.WithValue(c.Request.Context(), config.EmailHeader, c.GetHeader(config.EmailHeader))
+
+	filter, err := requestFilterQuery(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	page, err := pageQuery(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	result, info, err := s.controller.SearchAllRequest(ctx, filter, page)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	setPageHeaders(c, info)
 	c.IndentedJSON(http.StatusOK, result)
 }
 
@@ -237,6 +415,16 @@ func (s *Shop) UpdatePayment(c *gin.Context) {
 		return
 	}
 
+	version, err := ifMatch(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
 	var payment entity.Payment
 	if err := c.BindJSON(&payment); err != nil {
 		c.IndentedJSON(http.StatusBadRequest, struct {
@@ -246,10 +434,11 @@ func (s *Shop) UpdatePayment(c *gin.Context) {
 		})
 		return
 	}
+	payment.Version = version
 
-	err := s.controller.UpdatePayment(ctx, id, &payment)
+	err = s.controller.UpdatePayment(ctx, id, &payment)
 	if err != nil {
-		c.IndentedJSON(http.StatusBadRequest, struct {
+		c.IndentedJSON(updateStatus(err), struct {
 			Error string
 		}{
 			err.Error(),
@@ -257,9 +446,39 @@ func (s *Shop) UpdatePayment(c *gin.Context) {
 		return
 	}
 
+	setETag(c, payment.Version)
 	c.IndentedJSON(http.StatusOK, struct{}{})
 }
 
+// GetPayment gets a Payment, with its version as the ETag to send back in
+// If-Match when updating it.
+func (s *Shop) GetPayment(c *gin.Context) {
+	ctx := context.WithValue(c.Request.Context(), config.EmailHeader, c.GetHeader(config.EmailHeader))
+
+	id := c.Param("id")
+	if id == "" {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			"invalid ID",
+		})
+		return
+	}
+
+	payment, err := s.controller.GetPayment(ctx, id)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	setETag(c, payment.Version)
+	c.IndentedJSON(http.StatusOK, payment)
+}
+
 // GetPayments searches for Payments of a store.
 func (s *Shop) GetPayments(c *gin.Context) {
 	ctx := context.WithValue(c.Request.Context(), config.EmailHeader, c.GetHeader(config.EmailHeader))
@@ -274,7 +493,17 @@ func (s *Shop) GetPayments(c *gin.Context) {
 		return
 	}
 
-	result, err := s.controller.GetPayments(ctx, storeID)
+	page, err := pageQuery(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	result, info, err := s.controller.GetPayments(ctx, storeID, page)
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, struct {
 			Error string
@@ -284,6 +513,7 @@ func (s *Shop) GetPayments(c *gin.Context) {
 		return
 	}
 
+	setPageHeaders(c, info)
 	c.IndentedJSON(http.StatusOK, result)
 }
 
@@ -291,12 +521,27 @@ func (s *Shop) GetPayments(c *gin.Context) {
 func (s *Shop) SearchPayments(c *gin.Context) {
 	ctx := context.WithValue(c.Request.Context(), config.EmailHeader, c.GetHeader(config.EmailHeader))
 
-	result, err := s.controller.SearchPayment(
-		ctx,
-		c.Query("status"),
-		c.Query("initialDate"),
-		c.Query("endDate"),
-	)
+	filter, err := paymentFilterQuery(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	page, err := pageQuery(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, struct {
+			Error string
+		}{
+			err.Error(),
+		})
+		return
+	}
+
+	result, info, err := s.controller.SearchPayment(ctx, filter, page)
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, struct {
 			Error string
@@ -306,5 +551,6 @@ func (s *Shop) SearchPayments(c *gin.Context) {
 		return
 	}
 
+	setPageHeaders(c, info)
 	c.IndentedJSON(http.StatusOK, result)
 }
@@ -0,0 +1,282 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/restore/shop/config"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/fake"
+	"github.com/restore/shop/fixture"
+	"github.com/restore/shop/handler"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serve routes the request to a Shop handler on c and returns the response.
+func serve(c *fake.Controller, req *http.Request) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewShop(c)
+	router := gin.New()
+	router.POST("/private/request", h.CreateRequest)
+	router.PUT("/private/request/:id", h.UpdateRequest)
+	router.GET("/private/request/search/:storeID", h.SearchRequest)
+	router.POST("/private/confirm-request/:paymentID", h.ConfirmRequest)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateRequestHandler(t *testing.T) {
+	var got *entity.Create
+	c := &fake.Controller{
+		CreateRequestFunc: func(ctx context.Context, request *entity.Create) (string, error) {
+			got = request
+			return "7", nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/private/request", strings.NewReader(`{"items":[{"product_id":10,"store_id":1,"price":100}]}`))
+	w := serve(c, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("code = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	var body struct{ ID string }
+	err := json.Unmarshal(w.Body.Bytes(), &body)
+	if err != nil || body.ID != "7" {
+		t.Errorf("body = %s, want ID 7", w.Body)
+	}
+	if got == nil || len(got.Items) != 1 || got.Items[0].ProductID != 10 {
+		t.Errorf("request = %+v, want one item of product 10", got)
+	}
+}
+
+func TestCreateRequestHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  error
+	}{
+		{"invalid body", `{"items":`, nil},
+		{"controller error", `{"items":[]}`, errors.New("product not found")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fake.Controller{
+				CreateRequestFunc: func(context.Context, *entity.Create) (string, error) {
+					return "", tt.err
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/private/request", strings.NewReader(tt.body))
+			w := serve(c, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("code = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestUpdateRequestHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		ifMatch  string
+		err      error
+		want     int
+		wantETag string
+	}{
+		{"no If-Match", "", nil, http.StatusOK, `"2"`},
+		{"If-Match", `"1"`, nil, http.StatusOK, `"2"`},
+		{"weak If-Match", `W/"1"`, nil, http.StatusOK, `"2"`},
+		{"invalid If-Match", `"abc"`, nil, http.StatusBadRequest, ""},
+		{"conflict", `"1"`, entity.ErrVersionConflict, http.StatusConflict, ""},
+		{"unauthorized", "", entity.ErrUnauthorized, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotVersion int
+			var gotEmail any
+			c := &fake.Controller{
+				UpdateRequestFunc: func(ctx context.Context, id string, request *entity.Request) error {
+					gotVersion = request.Version
+					gotEmail = ctx.Value(config.EmailHeader)
+					if tt.err != nil {
+						return tt.err
+					}
+					request.Version = 2
+					return nil
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/private/request/1", strings.NewReader(`{"status":"sent","track":"BR123"}`))
+			req.Header.Set(config.EmailHeader, fixture.AdminEmail)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := serve(c, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("code = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if etag := w.Header().Get("ETag"); etag != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", etag, tt.wantETag)
+			}
+			if tt.want == http.StatusBadRequest && tt.err == nil {
+				return
+			}
+			if tt.ifMatch != "" && gotVersion != 1 {
+				t.Errorf("version = %d, want 1 from If-Match", gotVersion)
+			}
+			if gotEmail != fixture.AdminEmail {
+				t.Errorf("email = %v, want %s", gotEmail, fixture.AdminEmail)
+			}
+		})
+	}
+}
+
+func TestGetThenUpdateConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	shop := fixture.NewShop()
+	req := fixture.Request(1, 10, 2, "preparing")
+	err := shop.Repository.CreateRequest(context.Background(), &req)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	payment := fixture.Payment(1, 10, 100, "pending")
+	_, err = shop.Repository.CreatePayment(context.Background(), &payment)
+	if err != nil {
+		t.Fatalf("error creating payment: %v", err)
+	}
+
+	h := handler.NewShop(shop)
+	router := gin.New()
+	router.GET("/private/request/search/:storeID", h.SearchRequest)
+	router.GET("/private/request/:id", h.GetRequest)
+	router.PUT("/private/request/:id", h.UpdateRequest)
+	router.GET("/private/payment/:id", h.GetPayment)
+	router.PUT("/private/payment/:id", h.UpdatePayment)
+
+	send := func(method, path, ifMatch, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set(config.EmailHeader, fixture.AdminEmail)
+		if ifMatch != "" {
+			r.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"request", "/private/request/1", `{"status":"sent","track":"BR123"}`},
+		{"payment", "/private/payment/1", `{"status":"paid"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := send(http.MethodGet, tt.path, "", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("get code = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			etag := w.Header().Get("ETag")
+			if etag != `"1"` {
+				t.Fatalf("ETag = %q, want %q", etag, `"1"`)
+			}
+
+			w = send(http.MethodPut, tt.path, etag, tt.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("update code = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+
+			// A second client still holding the first ETag.
+			w = send(http.MethodPut, tt.path, etag, tt.body)
+			if w.Code != http.StatusConflict {
+				t.Errorf("stale update code = %d, want %d: %s", w.Code, http.StatusConflict, w.Body)
+			}
+		})
+	}
+}
+
+func TestSearchRequestHandler(t *testing.T) {
+	var (
+		gotStore  string
+		gotFilter entity.RequestFilter
+		gotPage   entity.Page
+	)
+	c := &fake.Controller{
+		SearchRequestFunc: func(ctx context.Context, storeID string, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error) {
+			gotStore, gotFilter, gotPage = storeID, filter, page
+			return []entity.Request{fixture.Request(1, 10, 2, "preparing")}, entity.PageInfo{Total: 3, NextCursor: "next"}, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/private/request/search/1?status=preparing,sent&limit=1&sort=asc", nil)
+	w := serve(c, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("code = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if total := w.Header().Get("X-Total-Count"); total != "3" {
+		t.Errorf("X-Total-Count = %q, want 3", total)
+	}
+	if cursor := w.Header().Get("X-Next-Cursor"); cursor != "next" {
+		t.Errorf("X-Next-Cursor = %q, want next", cursor)
+	}
+	if gotStore != "1" || len(gotFilter.Status) != 2 || gotPage.Limit != 1 || gotPage.Sort != entity.SortAsc {
+		t.Errorf("search = %s %+v %+v, want store 1, two statuses, limit 1 ascending", gotStore, gotFilter, gotPage)
+	}
+
+	var body []entity.Request
+	err := json.Unmarshal(w.Body.Bytes(), &body)
+	if err != nil || len(body) != 1 {
+		t.Errorf("body = %s, want one request", w.Body)
+	}
+}
+
+func TestSearchRequestHandlerInvalidQuery(t *testing.T) {
+	called := false
+	c := &fake.Controller{
+		SearchRequestFunc: func(context.Context, string, entity.RequestFilter, entity.Page) ([]entity.Request, entity.PageInfo, error) {
+			called = true
+			return nil, entity.PageInfo{}, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/private/request/search/1?limit=ten", nil)
+	w := serve(c, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("code = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if called {
+		t.Error("controller called with an invalid query")
+	}
+}
+
+func TestConfirmRequestHandler(t *testing.T) {
+	var got string
+	c := &fake.Controller{
+		ConfirmRequestFunc: func(ctx context.Context, paymentID string) error {
+			got = paymentID
+			return nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/private/confirm-request/5", nil)
+	w := serve(c, req)
+
+	if w.Code != http.StatusOK || got != "5" {
+		t.Errorf("code = %d, payment = %q, want %d on payment 5", w.Code, got, http.StatusOK)
+	}
+}
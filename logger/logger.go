@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	RequestIDKey    = "x-request-id"
+)
+
+type Config struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+type requestIDKey struct{}
+
+// New builds the logger described by cfg. Format is either json or console.
+func New(cfg *Config) (*zap.Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	zapCfg := zap.NewProductionConfig()
+	if cfg.Format == "console" {
+		zapCfg = zap.NewDevelopmentConfig()
+	}
+	zapCfg.Level = zap.NewAtomicLevelAt(level)
+
+	return zapCfg.Build()
+}
+
+// NewRequestID generates a random request ID.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// For returns log annotated with the request ID of ctx.
+func For(ctx context.Context, log *zap.Logger) *zap.Logger {
+	if id := RequestID(ctx); id != "" {
+		return log.With(zap.String("request_id", id))
+	}
+	return log
+}
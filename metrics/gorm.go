@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const startKey = "metrics:start"
+
+// BeforeDB starts timing an operation of a *gorm.DB.
+func BeforeDB(operation string, tx *gorm.DB) {
+	tx.InstanceSet(startKey, time.Now())
+}
+
+// AfterDB observes the duration of an operation started by BeforeDB.
+func AfterDB(operation string, tx *gorm.DB) {
+	start, ok := tx.InstanceGet(startKey)
+	if !ok {
+		return
+	}
+	failed := tx.Error != nil && tx.Error != gorm.ErrRecordNotFound
+	ObserveDB(operation, tx.Statement.Table, failed, time.Since(start.(time.Time)))
+}
@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"time"
+)
+
+const namespace = "shop"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, per route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests, per route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of database queries, per operation and table.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "table", "error"})
+
+	dbRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_retries_total",
+		Help:      "Repository calls retried after a transient database error, per method.",
+	}, []string{"method"})
+
+	grpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_requests_total",
+		Help:      "gRPC calls made to upstreams, per method and code.",
+	}, []string{"upstream", "method", "code"})
+
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_request_duration_seconds",
+		Help:      "Duration of gRPC calls made to upstreams, per method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"upstream", "method"})
+
+	breakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_open",
+		Help:      "Whether the circuit breaker of an upstream is open (1) or not (0).",
+	}, []string{"upstream"})
+
+	breakerRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_rejected_total",
+		Help:      "Calls rejected by the circuit breaker of an upstream.",
+	}, []string{"upstream"})
+
+	ordersCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_created_total",
+		Help:      "Orders created at checkout.",
+	})
+
+	requestsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "requests_created_total",
+		Help:      "Requests created at checkout.",
+	})
+
+	ordersConfirmed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_confirmed_total",
+		Help:      "Orders confirmed after payment.",
+	})
+
+	snapshotsSkipped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "snapshots_skipped_total",
+		Help:      "Requests created without a product snapshot, as the product service was unavailable.",
+	})
+
+	paymentVolume = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payment_volume_total",
+		Help:      "Amount charged at checkout, taxes included.",
+	})
+)
+
+func ObserveHTTP(method, route, status string, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, status).Inc()
+	httpDuration.WithLabelValues(method, route, status).Observe(duration.Seconds())
+}
+
+func ObserveDB(operation, table string, failed bool, duration time.Duration) {
+	errLabel := "false"
+	if failed {
+		errLabel = "true"
+	}
+	dbDuration.WithLabelValues(operation, table, errLabel).Observe(duration.Seconds())
+}
+
+func DBRetried(method string) {
+	dbRetries.WithLabelValues(method).Inc()
+}
+
+func ObserveGRPC(upstream, method, code string, duration time.Duration) {
+	grpcRequests.WithLabelValues(upstream, method, code).Inc()
+	grpcDuration.WithLabelValues(upstream, method).Observe(duration.Seconds())
+}
+
+func SetBreakerOpen(upstream string, open bool) {
+	value := 0.0
+	if open {
+		value = 1
+	}
+	breakerState.WithLabelValues(upstream).Set(value)
+}
+
+func BreakerRejected(upstream string) {
+	breakerRejected.WithLabelValues(upstream).Inc()
+}
+
+// OrderCreated counts an order of items requests charged amount.
+func OrderCreated(items int, amount float64) {
+	ordersCreated.Inc()
+	requestsCreated.Add(float64(items))
+	paymentVolume.Add(amount)
+}
+
+func OrderConfirmed() {
+	ordersConfirmed.Inc()
+}
+
+func SnapshotSkipped() {
+	snapshotsSkipped.Inc()
+}
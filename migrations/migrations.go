@@ -0,0 +1,510 @@
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	versionTable = "schema_migrations"
+
+	// lockName names the lock held while migrating, so that replicas starting
+	// together don't run the same migration twice.
+	lockName = "shop_schema_migrations"
+	lockKey  = 7307182019
+)
+
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+var (
+	fileName  = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	dollarTag = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+)
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Migration represents data about a schema migration.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status represents data about a migration and whether it was applied.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type Runner struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+// NewRunner builds the runner of the migrations written for driver, one of
+// mysql, postgres or sqlite.
+func NewRunner(db *sql.DB, driver string) (*Runner, error) {
+	fsys, err := fs.Sub(files, driver)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+
+	return &Runner{
+		db:         db,
+		driver:     driver,
+		migrations: migrations,
+	}, nil
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	result := []Migration{}
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d has no up script", m.Version)
+		}
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+func (r *Runner) init(ctx context.Context) error {
+	dateType := "datetime"
+	if r.driver == "postgres" {
+		dateType = "TIMESTAMP"
+	}
+
+	_, err := r.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+versionTable+` (
+    version INT PRIMARY KEY,
+    name VARCHAR(255),
+    checksum CHAR(64),
+    applied_at `+dateType+`
+)`)
+	return err
+}
+
+// applied returns the checksum of every applied migration, by version.
+func (r *Runner) applied(ctx context.Context) (map[int]string, map[int]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT version, checksum, applied_at FROM `+versionTable)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	checksums := map[int]string{}
+	dates := map[int]time.Time{}
+	for rows.Next() {
+		var (
+			version   int
+			checksum  string
+			appliedAt time.Time
+		)
+		err := rows.Scan(&version, &checksum, &appliedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+		checksums[version] = checksum
+		dates[version] = appliedAt
+	}
+	return checksums, dates, rows.Err()
+}
+
+// verify checks that no applied migration was changed after being applied.
+func (r *Runner) verify(checksums map[int]string) error {
+	for _, m := range r.migrations {
+		checksum, ok := checksums[m.Version]
+		if ok && checksum != m.Checksum {
+			return fmt.Errorf("migration %d_%s was changed after being applied", m.Version, m.Name)
+		}
+	}
+	return nil
+}
+
+// Up applies every pending migration, in order. It returns the applied ones.
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	err = r.init(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	checksums, _, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = r.verify(checksums)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Migration{}
+	for _, m := range r.migrations {
+		if _, ok := checksums[m.Version]; ok {
+			continue
+		}
+
+		err = r.apply(ctx, m.Up, func(db execer) error {
+			return r.record(ctx, db, m)
+		})
+		if err != nil {
+			return result, fmt.Errorf("error applying migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// Down reverts the last n applied migrations. It returns the reverted ones.
+func (r *Runner) Down(ctx context.Context, n int) ([]Migration, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	err = r.init(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	checksums, _, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = r.verify(checksums)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Migration{}
+	for i := len(r.migrations) - 1; i >= 0 && len(result) < n; i-- {
+		m := r.migrations[i]
+		if _, ok := checksums[m.Version]; !ok {
+			continue
+		}
+		if m.Down == "" {
+			return result, fmt.Errorf("migration %d_%s can't be reverted", m.Version, m.Name)
+		}
+
+		err = r.apply(ctx, m.Down, func(db execer) error {
+			_, err := db.ExecContext(ctx, r.bind(`DELETE FROM `+versionTable+` WHERE version = ?`), m.Version)
+			return err
+		})
+		if err != nil {
+			return result, fmt.Errorf("error reverting migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// Force records version and every earlier pending migration as applied
+// without running them, as the baseline of schemas migrated by hand before
+// the runner existed.
+func (r *Runner) Force(ctx context.Context, version int) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = r.init(ctx)
+	if err != nil {
+		return err
+	}
+
+	known := false
+	for _, m := range r.migrations {
+		known = known || m.Version == version
+	}
+	if !known {
+		return fmt.Errorf("unknown migration %d", version)
+	}
+
+	checksums, _, err := r.applied(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range r.migrations {
+		if _, ok := checksums[m.Version]; ok || m.Version > version {
+			continue
+		}
+		err = r.record(ctx, r.db, m)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Status returns every migration and when it was applied.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	err := r.init(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	checksums, dates, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = r.verify(checksums)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Status{}
+	for _, m := range r.migrations {
+		status := Status{Migration: m}
+		if appliedAt, ok := dates[m.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+func (r *Runner) record(ctx context.Context, db execer, m Migration) error {
+	_, err := db.ExecContext(ctx,
+		r.bind(`INSERT INTO `+versionTable+` (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`),
+		m.Version, m.Name, m.Checksum, time.Now(),
+	)
+	return err
+}
+
+// lock takes the migration lock of the database, waiting for other runners
+// to release it. SQLite has no such lock; there, a concurrent runner fails on
+// the version it also recorded, rolling its migration back.
+func (r *Runner) lock(ctx context.Context) (func(), error) {
+	var lock, unlock string
+	var args []interface{}
+	switch r.driver {
+	case "postgres":
+		lock, unlock = `SELECT pg_advisory_lock($1)`, `SELECT pg_advisory_unlock($1)`
+		args = []interface{}{lockKey}
+	case "mysql":
+		lock, unlock = `SELECT GET_LOCK(?, -1)`, `SELECT RELEASE_LOCK(?)`
+		args = []interface{}{lockName}
+	default:
+		return func() {}, nil
+	}
+
+	// Both locks belong to the session, so they are taken and released on the
+	// same connection.
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.driver == "mysql" {
+		// GET_LOCK returns 1 once taken, instead of failing.
+		var locked sql.NullInt64
+		err = conn.QueryRowContext(ctx, lock, args...).Scan(&locked)
+		if err == nil && locked.Int64 != 1 {
+			err = fmt.Errorf("could not take the %s lock", lockName)
+		}
+	} else {
+		_, err = conn.ExecContext(ctx, lock, args...)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error taking the migration lock: %w", err)
+	}
+
+	return func() {
+		conn.ExecContext(context.Background(), unlock, args...)
+		conn.Close()
+	}, nil
+}
+
+// apply runs every statement of script, one at a time, then record. Both run
+// in a single transaction, so a failure leaves neither the schema changed nor
+// the version recorded, except on MySQL, which commits every schema change
+// at once.
+func (r *Runner) apply(ctx context.Context, script string, record func(db execer) error) error {
+	if r.driver == "mysql" {
+		err := exec(ctx, r.db, script)
+		if err != nil {
+			return err
+		}
+		return record(r.db)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = exec(ctx, tx, script)
+	if err != nil {
+		return err
+	}
+	err = record(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func exec(ctx context.Context, db execer, script string) error {
+	for _, statement := range statements(script) {
+		_, err := db.ExecContext(ctx, statement)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statements splits script on the semicolons outside of quotes, comments and
+// dollar-quoted bodies. Statements made only of comments are dropped.
+func statements(script string) []string {
+	var (
+		result  []string
+		start   int
+		content bool
+	)
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(script, i)
+		case strings.HasPrefix(script[i:], "--"):
+			i = skipUntil(script, i, "\n")
+			continue
+		case strings.HasPrefix(script[i:], "/*"):
+			i = skipUntil(script, i+2, "*/")
+			continue
+		case c == '$' && dollarTag.MatchString(script[i:]):
+			tag := dollarTag.FindString(script[i:])
+			i = skipUntil(script, i+len(tag), tag)
+		case c == ';':
+			if content {
+				result = append(result, strings.TrimSpace(script[start:i]))
+			}
+			start, content = i+1, false
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			continue
+		}
+		content = true
+	}
+	if content {
+		result = append(result, strings.TrimSpace(script[start:]))
+	}
+	return result
+}
+
+// skipQuoted returns the index of the quote closing the one at i. Doubled
+// quotes are part of the quoted text.
+func skipQuoted(script string, i int) int {
+	quote := script[i]
+	for j := i + 1; j < len(script); j++ {
+		if script[j] != quote {
+			continue
+		}
+		if j+1 < len(script) && script[j+1] == quote {
+			j++
+			continue
+		}
+		return j
+	}
+	return len(script) - 1
+}
+
+// skipUntil returns the index of the last byte of the first end found from
+// i, or of the last byte of script.
+func skipUntil(script string, i int, end string) int {
+	j := strings.Index(script[i:], end)
+	if j < 0 {
+		return len(script) - 1
+	}
+	return i + j + len(end) - 1
+}
+
+// bind rewrites the ? placeholders of query for the driver.
+func (r *Runner) bind(query string) string {
+	if r.driver != "postgres" {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/glebarez/go-sqlite"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+var databases atomic.Int64
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:migrations%d?mode=memory&cache=shared", databases.Add(1)))
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+	return db
+}
+
+func TestStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"plain", "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n", []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"}},
+		{"no trailing semicolon", "SELECT 1", []string{"SELECT 1"}},
+		{"quoted semicolon", "INSERT INTO a VALUES ('x;y');", []string{"INSERT INTO a VALUES ('x;y')"}},
+		{"doubled quote", "INSERT INTO a VALUES ('it''s;');SELECT 2;", []string{"INSERT INTO a VALUES ('it''s;')", "SELECT 2"}},
+		{"quoted identifier", "SELECT \"a;b\" FROM `c;d`;", []string{"SELECT \"a;b\" FROM `c;d`"}},
+		{"line comment", "-- first; statement\nSELECT 1;", []string{"-- first; statement\nSELECT 1"}},
+		{"block comment", "SELECT /* ; */ 1;", []string{"SELECT /* ; */ 1"}},
+		{"only comments", "SELECT 1;\n-- done;\n/* end */\n", []string{"SELECT 1"}},
+		{"dollar quoted", "CREATE FUNCTION f() RETURNS INT AS $$ BEGIN RETURN 1; END $$ LANGUAGE plpgsql;SELECT 1;", []string{"CREATE FUNCTION f() RETURNS INT AS $$ BEGIN RETURN 1; END $$ LANGUAGE plpgsql", "SELECT 1"}},
+		{"tagged dollar quoted", "DO $body$ BEGIN PERFORM 1; END $body$;", []string{"DO $body$ BEGIN PERFORM 1; END $body$"}},
+		{"placeholder", "SELECT $1; SELECT 2;", []string{"SELECT $1", "SELECT 2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statements(tt.script)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statements = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpRollsBackFailedMigration(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	r := &Runner{
+		db:     db,
+		driver: "sqlite",
+		migrations: []Migration{
+			{Version: 1, Name: "create_a", Up: "CREATE TABLE a (id INT);", Checksum: "1"},
+			{Version: 2, Name: "broken", Up: "CREATE TABLE b (id INT);\nINSERT INTO missing VALUES (1);", Checksum: "2"},
+		},
+	}
+
+	applied, err := r.Up(ctx)
+	if err == nil {
+		t.Fatal("expected an error applying the broken migration")
+	}
+	if len(applied) != 1 || applied[0].Version != 1 {
+		t.Fatalf("applied = %v, want only migration 1", applied)
+	}
+
+	var tables int
+	err = db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'b'`).Scan(&tables)
+	if err != nil {
+		t.Fatalf("error reading schema: %v", err)
+	}
+	if tables != 0 {
+		t.Error("table b of the failed migration was kept")
+	}
+
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		t.Fatalf("error reading status: %v", err)
+	}
+	if statuses[0].AppliedAt == nil || statuses[1].AppliedAt != nil {
+		t.Errorf("only migration 1 should be recorded, got %+v", statuses)
+	}
+}
+
+func TestForceBaseline(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	r, err := NewRunner(db, "sqlite")
+	if err != nil {
+		t.Fatalf("error loading migrations: %v", err)
+	}
+
+	// The tables of the numbered scripts 0.sql and 1.sql once run by hand.
+	for _, m := range r.migrations[:2] {
+		err = exec(ctx, db, m.Up)
+		if err != nil {
+			t.Fatalf("error running %d_%s: %v", m.Version, m.Name, err)
+		}
+	}
+
+	err = r.Force(ctx, 2)
+	if err != nil {
+		t.Fatalf("error forcing version 2: %v", err)
+	}
+	applied, err := r.Up(ctx)
+	if err != nil {
+		t.Fatalf("error migrating after the baseline: %v", err)
+	}
+	if len(applied) != len(r.migrations)-2 || applied[0].Version != 3 {
+		t.Errorf("applied %v, want every migration after 2", applied)
+	}
+}
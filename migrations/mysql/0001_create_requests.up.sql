@@ -1,8 +1,4 @@
-CREATE DATABASE IF NOT EXISTS shopdb;
-
-USE shopdb;
-
-CREATE TABLE requests (
+CREATE TABLE IF NOT EXISTS requests (
     id INT(6) AUTO_INCREMENT PRIMARY KEY,
     payment_id VARCHAR(100),
     price FLOAT,
@@ -13,4 +9,4 @@ CREATE TABLE requests (
     store_id INT,
     product_id INT,
     user_id INT
-)
\ No newline at end of file
+);
@@ -0,0 +1,6 @@
+DROP INDEX idx_requests_payment_id ON requests;
+DROP INDEX idx_requests_store_id ON requests;
+DROP INDEX idx_requests_user_id ON requests;
+DROP INDEX idx_requests_status_created_at ON requests;
+DROP INDEX idx_payments_store_id ON payments;
+DROP INDEX idx_payments_status_created_at ON payments;
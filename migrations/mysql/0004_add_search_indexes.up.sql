@@ -0,0 +1,6 @@
+CREATE INDEX idx_requests_payment_id ON requests (payment_id);
+CREATE INDEX idx_requests_store_id ON requests (store_id, created_at, id);
+CREATE INDEX idx_requests_user_id ON requests (user_id, created_at, id);
+CREATE INDEX idx_requests_status_created_at ON requests (status, created_at);
+CREATE INDEX idx_payments_store_id ON payments (store_id, created_at, id);
+CREATE INDEX idx_payments_status_created_at ON payments (status, created_at);
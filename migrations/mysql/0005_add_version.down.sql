@@ -0,0 +1,2 @@
+ALTER TABLE requests DROP COLUMN version;
+ALTER TABLE payments DROP COLUMN version;
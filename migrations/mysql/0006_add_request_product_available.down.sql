@@ -0,0 +1 @@
+ALTER TABLE requests DROP COLUMN product_available;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS requests (
+    id SERIAL PRIMARY KEY,
+    payment_id VARCHAR(100),
+    price DOUBLE PRECISION,
+    tax DOUBLE PRECISION,
+    track VARCHAR(100),
+    status VARCHAR(100),
+    created_at TIMESTAMP,
+    store_id INT,
+    product_id INT,
+    user_id INT
+);
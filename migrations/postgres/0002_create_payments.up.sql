@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS payments (
+    id SERIAL PRIMARY KEY,
+    total DOUBLE PRECISION,
+    pix VARCHAR(100),
+    status VARCHAR(100),
+    created_at TIMESTAMP,
+    store_id INT,
+    product_id INT
+);
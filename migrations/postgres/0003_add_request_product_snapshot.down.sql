@@ -0,0 +1,8 @@
+ALTER TABLE requests
+    DROP COLUMN product_name,
+    DROP COLUMN product_description,
+    DROP COLUMN product_categories,
+    DROP COLUMN product_size,
+    DROP COLUMN product_price,
+    DROP COLUMN product_tax,
+    DROP COLUMN product_images;
@@ -0,0 +1,8 @@
+ALTER TABLE requests
+    ADD COLUMN product_name VARCHAR(255),
+    ADD COLUMN product_description TEXT,
+    ADD COLUMN product_categories VARCHAR(255),
+    ADD COLUMN product_size VARCHAR(100),
+    ADD COLUMN product_price DOUBLE PRECISION,
+    ADD COLUMN product_tax DOUBLE PRECISION,
+    ADD COLUMN product_images TEXT;
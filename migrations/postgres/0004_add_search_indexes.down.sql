@@ -0,0 +1,6 @@
+DROP INDEX idx_requests_payment_id;
+DROP INDEX idx_requests_store_id;
+DROP INDEX idx_requests_user_id;
+DROP INDEX idx_requests_status_created_at;
+DROP INDEX idx_payments_store_id;
+DROP INDEX idx_payments_status_created_at;
@@ -0,0 +1,2 @@
+ALTER TABLE requests ADD COLUMN product_available BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE requests SET product_available = TRUE WHERE product_name IS NOT NULL AND product_name <> '';
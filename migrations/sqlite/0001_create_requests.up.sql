@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS requests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    payment_id VARCHAR(100),
+    price REAL,
+    tax REAL,
+    track VARCHAR(100),
+    status VARCHAR(100),
+    created_at DATETIME,
+    store_id INTEGER,
+    product_id INTEGER,
+    user_id INTEGER
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS payments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    total REAL,
+    pix VARCHAR(100),
+    status VARCHAR(100),
+    created_at DATETIME,
+    store_id INTEGER,
+    product_id INTEGER
+);
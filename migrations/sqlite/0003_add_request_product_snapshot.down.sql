@@ -0,0 +1,7 @@
+ALTER TABLE requests DROP COLUMN product_name;
+ALTER TABLE requests DROP COLUMN product_description;
+ALTER TABLE requests DROP COLUMN product_categories;
+ALTER TABLE requests DROP COLUMN product_size;
+ALTER TABLE requests DROP COLUMN product_price;
+ALTER TABLE requests DROP COLUMN product_tax;
+ALTER TABLE requests DROP COLUMN product_images;
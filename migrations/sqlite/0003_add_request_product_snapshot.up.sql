@@ -0,0 +1,7 @@
+ALTER TABLE requests ADD COLUMN product_name VARCHAR(255);
+ALTER TABLE requests ADD COLUMN product_description TEXT;
+ALTER TABLE requests ADD COLUMN product_categories VARCHAR(255);
+ALTER TABLE requests ADD COLUMN product_size VARCHAR(100);
+ALTER TABLE requests ADD COLUMN product_price REAL;
+ALTER TABLE requests ADD COLUMN product_tax REAL;
+ALTER TABLE requests ADD COLUMN product_images TEXT;
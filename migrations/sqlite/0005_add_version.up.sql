@@ -0,0 +1,2 @@
+ALTER TABLE requests ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE payments ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"github.com/restore/shop/entity"
+	"gorm.io/gorm"
+	"time"
+)
+
+// filterAnalytics applies the dates of filter to query.
+func filterAnalytics(query *gorm.DB, filter entity.AnalyticsFilter) *gorm.DB {
+	if !filter.InitialDate.IsZero() {
+		query = query.Where("created_at > ?", filter.InitialDate)
+	}
+	if !filter.EndDate.IsZero() {
+		query = query.Where("created_at < ?", filter.EndDate)
+	}
+	return query
+}
+
+// GMV aggregates the sold Requests of every store per period.
+func (s *Shop) GMV(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.GMVPeriod, error) {
+	start, err := periodStart(s.db.Dialector.Name(), filter.Period, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []entity.GMVPeriod
+	err = s.withRetry(ctx, "GMV", false, func() error {
+		result = []entity.GMVPeriod{}
+		return filterAnalytics(s.db.WithContext(ctx).Model(&entity.Request{}), filter).
+			Select(start+` AS period,
+    COALESCE(SUM(price + tax), 0) AS gmv,
+    COALESCE(SUM(tax), 0) AS fees,
+    COUNT(DISTINCT payment_id) AS orders,
+    COUNT(DISTINCT store_id) AS stores`).
+			Where("status NOT IN ?", entity.UnsoldStatuses).
+			Group("period").
+			Order("period").
+			Scan(&result).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TopStores ranks the stores by their sales.
+func (s *Shop) TopStores(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.StoreSales, error) {
+	var result []entity.StoreSales
+	err := s.withRetry(ctx, "TopStores", false, func() error {
+		result = []entity.StoreSales{}
+		return filterAnalytics(s.db.WithContext(ctx).Model(&entity.Request{}), filter).
+			Select(`store_id,
+    COALESCE(SUM(price + tax), 0) AS gross_sales,
+    COUNT(DISTINCT payment_id) AS orders,
+    COUNT(*) AS requests`).
+			Where("status NOT IN ?", entity.UnsoldStatuses).
+			Group("store_id").
+			Order("gross_sales DESC, store_id").
+			Limit(filter.Limit).
+			Scan(&result).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TopCategories ranks the categories of the product snapshots by their sales.
+// Requests without a snapshot are left out.
+func (s *Shop) TopCategories(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.CategorySales, error) {
+	var result []entity.CategorySales
+	err := s.withRetry(ctx, "TopCategories", false, func() error {
+		result = []entity.CategorySales{}
+		return filterAnalytics(s.db.WithContext(ctx).Model(&entity.Request{}), filter).
+			Select(`product_categories AS category,
+    COALESCE(SUM(price + tax), 0) AS gross_sales,
+    COUNT(*) AS requests`).
+			Where("status NOT IN ?", entity.UnsoldStatuses).
+			Where("product_categories <> ''").
+			Group("product_categories").
+			Order("gross_sales DESC, category").
+			Limit(filter.Limit).
+			Scan(&result).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Checkouts counts the checkouts, one per payment, that were paid and the ones
+// still created after filter.AbandonedAfter.
+func (s *Shop) Checkouts(ctx context.Context, filter entity.AnalyticsFilter) (*entity.CheckoutStats, error) {
+	abandonedBefore := time.Now().Add(-filter.AbandonedAfter)
+
+	var result entity.CheckoutStats
+	err := s.withRetry(ctx, "Checkouts", false, func() error {
+		err := filterAnalytics(s.db.WithContext(ctx).Model(&entity.Request{}), filter).
+			Select(`COUNT(DISTINCT payment_id) AS checkouts,
+    COUNT(DISTINCT CASE WHEN status NOT IN ? THEN payment_id END) AS converted,
+    COUNT(DISTINCT CASE WHEN status = ? AND created_at < ? THEN payment_id END) AS abandoned,
+    COALESCE(SUM(CASE WHEN status = ? AND created_at < ? THEN price + tax END), 0) AS abandoned_value`,
+				entity.UnpaidStatuses,
+				entity.StatusCreated, abandonedBefore,
+				entity.StatusCreated, abandonedBefore,
+			).
+			Scan(&result).Error
+		if err != nil {
+			return err
+		}
+
+		result.ConversionRate = entity.Rate(result.Converted, result.Checkouts)
+		result.AbandonedRate = entity.Rate(result.Abandoned, result.Checkouts)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Refunds counts the paid Requests and the refunded ones.
+func (s *Shop) Refunds(ctx context.Context, filter entity.AnalyticsFilter) (*entity.RefundStats, error) {
+	var result entity.RefundStats
+	err := s.withRetry(ctx, "Refunds", false, func() error {
+		err := filterAnalytics(s.db.WithContext(ctx).Model(&entity.Request{}), filter).
+			Select(`COUNT(*) AS paid,
+    COUNT(CASE WHEN status = ? THEN 1 END) AS refunded,
+    COALESCE(SUM(CASE WHEN status = ? THEN price + tax END), 0) AS refunded_value`,
+				entity.StatusRefunded, entity.StatusRefunded,
+			).
+			Where("status NOT IN ?", entity.UnpaidStatuses).
+			Scan(&result).Error
+		if err != nil {
+			return err
+		}
+
+		result.RefundRate = entity.Rate(result.Refunded, result.Paid)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,175 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/fixture"
+	"github.com/restore/shop/repository"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGMV(t *testing.T) {
+	repo := sales(t)
+
+	result, err := repo.GMV(context.Background(), entity.AnalyticsFilter{Period: entity.PeriodMonth})
+	if err != nil {
+		t.Fatalf("error aggregating GMV: %v", err)
+	}
+
+	// February only has a refund.
+	want := []entity.GMVPeriod{
+		{Period: "2023-01-01", GMV: 1407, Fees: 38, Orders: 4, Stores: 2},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("GMV = %+v, want %+v", result, want)
+	}
+}
+
+func TestTopStores(t *testing.T) {
+	repo := sales(t)
+
+	tests := []struct {
+		name   string
+		filter entity.AnalyticsFilter
+		want   []entity.StoreSales
+	}{
+		{"every store", entity.AnalyticsFilter{Limit: 10}, []entity.StoreSales{
+			{StoreID: 2, GrossSales: 1000, Orders: 1, Requests: 1},
+			{StoreID: 1, GrossSales: 407, Orders: 3, Requests: 4},
+		}},
+		{"limited", entity.AnalyticsFilter{Limit: 1}, []entity.StoreSales{
+			{StoreID: 2, GrossSales: 1000, Orders: 1, Requests: 1},
+		}},
+		{"after a date", entity.AnalyticsFilter{Limit: 10, InitialDate: time.Date(2023, time.January, 5, 0, 0, 0, 0, time.UTC)}, []entity.StoreSales{
+			{StoreID: 1, GrossSales: 242, Orders: 2, Requests: 2},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.TopStores(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("error ranking stores: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("stores = %+v, want %+v", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopCategories(t *testing.T) {
+	repo := sales(t)
+
+	result, err := repo.TopCategories(context.Background(), entity.AnalyticsFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("error ranking categories: %v", err)
+	}
+
+	// The delivered Request without a snapshot is left out.
+	want := []entity.CategorySales{
+		{Category: "shoes", GrossSales: 1055, Requests: 2},
+		{Category: "clothes", GrossSales: 330, Requests: 2},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("categories = %+v, want %+v", result, want)
+	}
+}
+
+func TestCheckouts(t *testing.T) {
+	repo := sales(t)
+
+	result, err := repo.Checkouts(context.Background(), entity.AnalyticsFilter{AbandonedAfter: entity.DefaultAbandonedAfter})
+	if err != nil {
+		t.Fatalf("error counting checkouts: %v", err)
+	}
+
+	// Payment 3 was canceled, so it is neither converted nor abandoned.
+	want := &entity.CheckoutStats{
+		Checkouts:      7,
+		Converted:      5,
+		ConversionRate: 5.0 / 7,
+		Abandoned:      1,
+		AbandonedValue: 44,
+		AbandonedRate:  1.0 / 7,
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("checkouts = %+v, want %+v", result, want)
+	}
+
+	result, err = repo.Checkouts(context.Background(), entity.AnalyticsFilter{AbandonedAfter: 100 * 365 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("error counting checkouts: %v", err)
+	}
+	if result.Abandoned != 0 || result.AbandonedValue != 0 {
+		t.Errorf("checkouts = %+v, want none abandoned yet", result)
+	}
+}
+
+func TestRefunds(t *testing.T) {
+	repo := sales(t)
+
+	tests := []struct {
+		name   string
+		filter entity.AnalyticsFilter
+		want   *entity.RefundStats
+	}{
+		{"every request", entity.AnalyticsFilter{}, &entity.RefundStats{Paid: 6, Refunded: 1, RefundedValue: 11, RefundRate: 1.0 / 6}},
+		{"january", entity.AnalyticsFilter{EndDate: time.Date(2023, time.January, 31, 0, 0, 0, 0, time.UTC)}, &entity.RefundStats{Paid: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.Refunds(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("error counting refunds: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("refunds = %+v, want %+v", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyticsStoreStatuses(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewShop(fixture.DB(t), nil)
+
+	// Stores send their own statuses once a Request is paid.
+	for i, status := range []string{"created", "shipped", "canceled", "refunded"} {
+		req := fixture.Request(1, 10+i, 2, status)
+		req.PaymentID = strconv.Itoa(i + 1)
+		req.Price = 100
+		req.Tax = 10
+		err := repo.CreateRequest(ctx, &req)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+	}
+	filter := entity.AnalyticsFilter{Period: entity.PeriodMonth, Limit: 10, AbandonedAfter: entity.DefaultAbandonedAfter}
+
+	gmv, err := repo.GMV(ctx, filter)
+	if err != nil {
+		t.Fatalf("error aggregating GMV: %v", err)
+	}
+	if len(gmv) != 1 || gmv[0].GMV != 110 || gmv[0].Orders != 1 {
+		t.Errorf("GMV = %+v, want the shipped Request", gmv)
+	}
+
+	checkouts, err := repo.Checkouts(ctx, filter)
+	if err != nil {
+		t.Fatalf("error counting checkouts: %v", err)
+	}
+	if checkouts.Checkouts != 4 || checkouts.Converted != 2 {
+		t.Errorf("checkouts = %+v, want 2 of 4 converted", checkouts)
+	}
+
+	refunds, err := repo.Refunds(ctx, filter)
+	if err != nil {
+		t.Fatalf("error counting refunds: %v", err)
+	}
+	if refunds.Paid != 2 || refunds.Refunded != 1 {
+		t.Errorf("refunds = %+v, want 1 of 2 paid refunded", refunds)
+	}
+}
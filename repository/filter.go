@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"github.com/restore/shop/entity"
+	"gorm.io/gorm"
+)
+
+// filterPayments applies every criteria set in filter to query.
+func filterPayments(query *gorm.DB, filter entity.PaymentFilter) *gorm.DB {
+	if filter.StoreID != 0 {
+		query = query.Where("store_id = ?", filter.StoreID)
+	}
+	if filter.ProductID != 0 {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if len(filter.Status) > 0 {
+		query = query.Where("status IN ?", filter.Status)
+	}
+	if filter.MinTotal != nil {
+		query = query.Where("total >= ?", *filter.MinTotal)
+	}
+	if filter.MaxTotal != nil {
+		query = query.Where("total <= ?", *filter.MaxTotal)
+	}
+	if !filter.InitialDate.IsZero() {
+		query = query.Where("created_at > ?", filter.InitialDate)
+	}
+	if !filter.EndDate.IsZero() {
+		query = query.Where("created_at < ?", filter.EndDate)
+	}
+	return query
+}
+
+// filterRequests applies every criteria set in filter to query.
+func filterRequests(query *gorm.DB, filter entity.RequestFilter) *gorm.DB {
+	if filter.StoreID != 0 {
+		query = query.Where("store_id = ?", filter.StoreID)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.ProductID != 0 {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.PaymentID != "" {
+		query = query.Where("payment_id = ?", filter.PaymentID)
+	}
+	if len(filter.Status) > 0 {
+		query = query.Where("status IN ?", filter.Status)
+	}
+	if !filter.Unpaid {
+		query = query.Where("status != ?", entity.StatusCreated)
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("price <= ?", *filter.MaxPrice)
+	}
+	if !filter.InitialDate.IsZero() {
+		query = query.Where("created_at > ?", filter.InitialDate)
+	}
+	if !filter.EndDate.IsZero() {
+		query = query.Where("created_at < ?", filter.EndDate)
+	}
+	return query
+}
@@ -0,0 +1,146 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/fixture"
+	"github.com/restore/shop/repository"
+	"testing"
+	"time"
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func TestSearchPaymentFilter(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewShop(fixture.DB(t), nil)
+
+	payments := []entity.Payment{
+		fixture.Payment(1, 10, 50, "pending"),
+		fixture.Payment(1, 11, 150, "paid"),
+		fixture.Payment(2, 10, 250, "paid"),
+		fixture.Payment(2, 12, 350, "canceled"),
+	}
+	for i := range payments {
+		payments[i].CreatedAt = fixture.CreatedAt.Add(time.Duration(i) * time.Hour)
+		_, err := repo.CreatePayment(ctx, &payments[i])
+		if err != nil {
+			t.Fatalf("error creating payment: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter entity.PaymentFilter
+		want   []int
+	}{
+		{"no filter", entity.PaymentFilter{}, []int{4, 3, 2, 1}},
+		{"store", entity.PaymentFilter{StoreID: 1}, []int{2, 1}},
+		{"product", entity.PaymentFilter{ProductID: 10}, []int{3, 1}},
+		{"status", entity.PaymentFilter{Status: []string{"paid", "canceled"}}, []int{4, 3, 2}},
+		{"min total", entity.PaymentFilter{MinTotal: float(150)}, []int{4, 3, 2}},
+		{"max total", entity.PaymentFilter{MaxTotal: float(250)}, []int{3, 2, 1}},
+		{"total range", entity.PaymentFilter{MinTotal: float(100), MaxTotal: float(300)}, []int{3, 2}},
+		{"initial date", entity.PaymentFilter{InitialDate: fixture.CreatedAt.Add(time.Hour)}, []int{4, 3}},
+		{"end date", entity.PaymentFilter{EndDate: fixture.CreatedAt.Add(2 * time.Hour)}, []int{2, 1}},
+		{"date range", entity.PaymentFilter{InitialDate: fixture.CreatedAt, EndDate: fixture.CreatedAt.Add(3 * time.Hour)}, []int{3, 2}},
+		{"combined", entity.PaymentFilter{StoreID: 2, Status: []string{"paid"}, MinTotal: float(200)}, []int{3}},
+		{"no match", entity.PaymentFilter{StoreID: 3}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, info, err := repo.SearchPayment(ctx, tt.filter, entity.Page{})
+			if err != nil {
+				t.Fatalf("error searching payments: %v", err)
+			}
+
+			ids := []int{}
+			for _, payment := range result {
+				ids = append(ids, payment.ID)
+			}
+			assertIDs(t, ids, tt.want)
+			if info.Total != int64(len(tt.want)) {
+				t.Errorf("total = %d, want %d", info.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestSearchRequestFilter(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewShop(fixture.DB(t), nil)
+
+	requests := []entity.Request{
+		fixture.Request(1, 10, 100, "created"),
+		fixture.Request(1, 10, 100, "preparing"),
+		fixture.Request(1, 11, 101, "sent"),
+		fixture.Request(2, 10, 101, "delivered"),
+		fixture.Request(2, 12, 102, "canceled"),
+	}
+	for i := range requests {
+		requests[i].CreatedAt = fixture.CreatedAt.Add(time.Duration(i) * time.Hour)
+		requests[i].Price = float64(100 * (i + 1))
+		if i == 3 {
+			requests[i].PaymentID = "2"
+		}
+		err := repo.CreateRequest(ctx, &requests[i])
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter entity.RequestFilter
+		want   []int
+	}{
+		{"created left out by default", entity.RequestFilter{}, []int{5, 4, 3, 2}},
+		{"created left out when listed", entity.RequestFilter{Status: []string{"created"}}, []int{}},
+		{"created when unpaid", entity.RequestFilter{Status: []string{"created"}, Unpaid: true}, []int{1}},
+		{"unpaid", entity.RequestFilter{Unpaid: true}, []int{5, 4, 3, 2, 1}},
+		{"status", entity.RequestFilter{Status: []string{"created", "sent"}, Unpaid: true}, []int{3, 1}},
+		{"store", entity.RequestFilter{StoreID: 1}, []int{3, 2}},
+		{"user", entity.RequestFilter{UserID: 101}, []int{4, 3}},
+		{"product", entity.RequestFilter{ProductID: 10}, []int{4, 2}},
+		{"payment", entity.RequestFilter{PaymentID: "2"}, []int{4}},
+		{"min price", entity.RequestFilter{MinPrice: float(300)}, []int{5, 4, 3}},
+		{"max price", entity.RequestFilter{MaxPrice: float(300)}, []int{3, 2}},
+		{"price range", entity.RequestFilter{MinPrice: float(250), MaxPrice: float(450)}, []int{4, 3}},
+		{"initial date", entity.RequestFilter{InitialDate: fixture.CreatedAt.Add(2 * time.Hour)}, []int{5, 4}},
+		{"end date", entity.RequestFilter{EndDate: fixture.CreatedAt.Add(3 * time.Hour)}, []int{3, 2}},
+		{"date range", entity.RequestFilter{InitialDate: fixture.CreatedAt.Add(time.Hour), EndDate: fixture.CreatedAt.Add(4 * time.Hour)}, []int{4, 3}},
+		{"combined", entity.RequestFilter{StoreID: 1, ProductID: 10, Status: []string{"created", "preparing"}, Unpaid: true}, []int{2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, info, err := repo.SearchRequest(ctx, tt.filter, entity.Page{})
+			if err != nil {
+				t.Fatalf("error searching requests: %v", err)
+			}
+
+			ids := []int{}
+			for _, req := range result {
+				ids = append(ids, req.ID)
+			}
+			assertIDs(t, ids, tt.want)
+			if info.Total != int64(len(tt.want)) {
+				t.Errorf("total = %d, want %d", info.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+func assertIDs(t *testing.T, got, want []int) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("ids = %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("ids = %v, want %v", got, want)
+		}
+	}
+}
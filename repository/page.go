@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/restore/shop/entity"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor builds the opaque cursor pointing after the given row.
+func encodeCursor(createdAt time.Time, id int) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "," + strconv.Itoa(id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), ",", 2)
+	if len(parts) != 2 {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	return createdAt, id, nil
+}
+
+// paginate counts every row matching query and returns the page selected by
+// page, ordered by created_at and id. key extracts the cursor fields of a row.
+func paginate[T any](query *gorm.DB, page entity.Page, key func(T) (time.Time, int)) ([]T, entity.PageInfo, error) {
+	var info entity.PageInfo
+	query = query.Session(&gorm.Session{})
+
+	res := query.Model(new(T)).Count(&info.Total)
+	if res.Error != nil {
+		return nil, info, res.Error
+	}
+
+	result, next, err := seek(query, page, key)
+	if err != nil {
+		return nil, info, err
+	}
+	info.NextCursor = next
+	return result, info, nil
+}
+
+// seek returns the page of the rows matching query selected by page, ordered
+// by created_at and id, and the cursor of the next page, without counting the
+// rows. The cursor is empty on the last page.
+func seek[T any](query *gorm.DB, page entity.Page, key func(T) (time.Time, int)) ([]T, string, error) {
+	limit := page.Limit
+	if limit <= 0 {
+		limit = entity.DefaultLimit
+	}
+	if limit > entity.MaxLimit {
+		limit = entity.MaxLimit
+	}
+
+	op, order := "<", "created_at DESC, id DESC"
+	if page.Sort == entity.SortAsc {
+		op, order = ">", "created_at ASC, id ASC"
+	}
+
+	if page.Cursor != "" {
+		createdAt, id, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where(
+			"(created_at "+op+" ?) OR (created_at = ? AND id "+op+" ?)",
+			createdAt, createdAt, id,
+		)
+	}
+
+	var result []T
+	res := query.Order(order).Limit(limit + 1).Find(&result)
+	if res.Error != nil {
+		return nil, "", res.Error
+	}
+
+	next := ""
+	if len(result) > limit {
+		result = result[:limit]
+		next = encodeCursor(key(result[limit-1]))
+	}
+	return result, next, nil
+}
+
+func requestKey(r entity.Request) (time.Time, int) {
+	return r.CreatedAt, r.ID
+}
+
+func paymentKey(p entity.Payment) (time.Time, int) {
+	return p.CreatedAt, p.ID
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/restore/shop/entity"
+	"gorm.io/gorm"
+	"sort"
+)
+
+// periodStart returns the SQL expression of the first day, as YYYY-MM-DD, of
+// the period of column. Weeks start on Monday.
+func periodStart(dialect, period, column string) (string, error) {
+	switch dialect + "/" + period {
+	case DriverMySQL + "/" + entity.PeriodDay:
+		return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d')", column), nil
+	case DriverMySQL + "/" + entity.PeriodWeek:
+		return fmt.Sprintf("DATE_FORMAT(DATE_SUB(%[1]s, INTERVAL WEEKDAY(%[1]s) DAY), '%%Y-%%m-%%d')", column), nil
+	case DriverMySQL + "/" + entity.PeriodMonth:
+		return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-01')", column), nil
+	case DriverPostgres + "/" + entity.PeriodDay, DriverPostgres + "/" + entity.PeriodWeek, DriverPostgres + "/" + entity.PeriodMonth:
+		return fmt.Sprintf("TO_CHAR(DATE_TRUNC('%s', %s), 'YYYY-MM-DD')", period, column), nil
+	case DriverSQLite + "/" + entity.PeriodDay:
+		return fmt.Sprintf("strftime('%%Y-%%m-%%d', %s)", column), nil
+	case DriverSQLite + "/" + entity.PeriodWeek:
+		return fmt.Sprintf("date(%s, 'weekday 0', '-6 days')", column), nil
+	case DriverSQLite + "/" + entity.PeriodMonth:
+		return fmt.Sprintf("strftime('%%Y-%%m-01', %s)", column), nil
+	}
+	return "", fmt.Errorf("unsupported period %q for %s", period, dialect)
+}
+
+// filterReport applies the store and dates of filter to query.
+func filterReport(query *gorm.DB, filter entity.ReportFilter) *gorm.DB {
+	query = query.Where("store_id = ?", filter.StoreID)
+	if !filter.InitialDate.IsZero() {
+		query = query.Where("created_at > ?", filter.InitialDate)
+	}
+	if !filter.EndDate.IsZero() {
+		query = query.Where("created_at < ?", filter.EndDate)
+	}
+	return query
+}
+
+// salesColumns are the aggregates of the sold Requests of a period.
+const salesColumns = `COALESCE(SUM(price + tax), 0) AS gross_sales,
+    COALESCE(SUM(tax), 0) AS fees,
+    COALESCE(SUM(price), 0) AS net_payout,
+    COUNT(DISTINCT payment_id) AS orders,
+    COUNT(*) AS requests,
+    COALESCE(SUM(price + tax), 0) / CASE WHEN COUNT(DISTINCT payment_id) = 0 THEN 1 ELSE COUNT(DISTINCT payment_id) END AS average_ticket`
+
+// SalesReport aggregates the Requests of a store per period and in total.
+func (s *Shop) SalesReport(ctx context.Context, filter entity.ReportFilter) (*entity.SalesReport, error) {
+	start, err := periodStart(s.db.Dialector.Name(), filter.Period, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.SalesReport{
+		StoreID: filter.StoreID,
+		Period:  filter.Period,
+	}
+	err = s.withRetry(ctx, "SalesReport", false, func() error {
+		var periods []entity.SalesPeriod
+		res := filterReport(s.db.WithContext(ctx).Model(&entity.Request{}), filter).
+			Select(start+" AS period, "+salesColumns).
+			Where("status NOT IN ?", entity.UnsoldStatuses).
+			Group("period").
+			Order("period").
+			Scan(&periods)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		var total entity.SalesPeriod
+		res = filterReport(s.db.WithContext(ctx).Model(&entity.Request{}), filter).
+			Select(salesColumns).
+			Where("status NOT IN ?", entity.UnsoldStatuses).
+			Scan(&total)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		var statuses []struct {
+			Period string
+			Status string
+			Count  int64
+		}
+		res = filterReport(s.db.WithContext(ctx).Model(&entity.Request{}), filter).
+			Select(start+" AS period, status, COUNT(*) AS count").
+			Where("status != ?", entity.StatusCreated).
+			Group("period, status").
+			Scan(&statuses)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		byPeriod := map[string]map[string]int64{}
+		total.Statuses = map[string]int64{}
+		for _, row := range statuses {
+			if byPeriod[row.Period] == nil {
+				byPeriod[row.Period] = map[string]int64{}
+			}
+			byPeriod[row.Period][row.Status] += row.Count
+			total.Statuses[row.Status] += row.Count
+		}
+
+		// Periods without sales still have the breakdown of their statuses.
+		sold := map[string]bool{}
+		for _, p := range periods {
+			sold[p.Period] = true
+		}
+		for period := range byPeriod {
+			if !sold[period] {
+				periods = append(periods, entity.SalesPeriod{Period: period})
+			}
+		}
+		sort.Slice(periods, func(i, j int) bool {
+			return periods[i].Period < periods[j].Period
+		})
+		for i := range periods {
+			periods[i].Statuses = byPeriod[periods[i].Period]
+			if periods[i].Statuses == nil {
+				periods[i].Statuses = map[string]int64{}
+			}
+		}
+
+		report.Periods = periods
+		report.Total = total
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,189 @@
+package repository_test
+
+import (
+	"context"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/fixture"
+	"github.com/restore/shop/repository"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// sales stores the Requests the reports and analytics are checked on: a
+// week of January 2023 starting on Monday the 2nd, the next one, and
+// February.
+func sales(t *testing.T) *repository.Shop {
+	t.Helper()
+
+	day := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2023, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	rows := []struct {
+		store     int
+		payment   string
+		price     float64
+		tax       float64
+		status    string
+		createdAt time.Time
+		category  string
+	}{
+		{1, "1", 100, 10, "preparing", day(time.January, 2, 10), "clothes"},
+		{1, "1", 50, 5, "sent", day(time.January, 3, 10), "shoes"},
+		{1, "7", 20, 2, "delivered", day(time.January, 8, 23), ""},
+		{1, "2", 200, 20, "delivered", day(time.January, 10, 10), "clothes"},
+		{1, "3", 30, 3, "canceled", day(time.January, 10, 11), "clothes"},
+		{1, "4", 40, 4, "created", day(time.January, 11, 10), "clothes"},
+		{1, "5", 10, 1, "refunded", day(time.February, 1, 10), "clothes"},
+		{2, "6", 999, 1, "sent", day(time.January, 2, 10), "shoes"},
+	}
+
+	ctx := context.Background()
+	repo := repository.NewShop(fixture.DB(t), nil)
+	for i, row := range rows {
+		req := fixture.Request(row.store, 10+i, 2, row.status)
+		req.PaymentID = row.payment
+		req.Price = row.price
+		req.Tax = row.tax
+		req.CreatedAt = row.createdAt
+		req.Snapshot.Categories = row.category
+		err := repo.CreateRequest(ctx, &req)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+	}
+	return repo
+}
+
+func TestSalesReport(t *testing.T) {
+	repo := sales(t)
+
+	january := entity.SalesPeriod{
+		GrossSales: 407, Fees: 37, NetPayout: 370, Orders: 3, Requests: 4, AverageTicket: 407.0 / 3,
+		Statuses: map[string]int64{"preparing": 1, "sent": 1, "delivered": 2, "canceled": 1},
+	}
+	total := january
+	total.Statuses = map[string]int64{"preparing": 1, "sent": 1, "delivered": 2, "canceled": 1, "refunded": 1}
+
+	tests := []struct {
+		name   string
+		filter entity.ReportFilter
+		want   []entity.SalesPeriod
+		total  entity.SalesPeriod
+	}{
+		{
+			"month",
+			entity.ReportFilter{StoreID: 1, Period: entity.PeriodMonth},
+			[]entity.SalesPeriod{
+				withPeriod("2023-01-01", january),
+				{Period: "2023-02-01", Statuses: map[string]int64{"refunded": 1}},
+			},
+			total,
+		},
+		{
+			"week",
+			entity.ReportFilter{StoreID: 1, Period: entity.PeriodWeek},
+			[]entity.SalesPeriod{
+				{
+					Period: "2023-01-02", GrossSales: 187, Fees: 17, NetPayout: 170, Orders: 2, Requests: 3, AverageTicket: 93.5,
+					Statuses: map[string]int64{"preparing": 1, "sent": 1, "delivered": 1},
+				},
+				{
+					Period: "2023-01-09", GrossSales: 220, Fees: 20, NetPayout: 200, Orders: 1, Requests: 1, AverageTicket: 220,
+					Statuses: map[string]int64{"delivered": 1, "canceled": 1},
+				},
+				{Period: "2023-01-30", Statuses: map[string]int64{"refunded": 1}},
+			},
+			total,
+		},
+		{
+			"day between dates",
+			entity.ReportFilter{
+				StoreID:     1,
+				Period:      entity.PeriodDay,
+				InitialDate: time.Date(2023, time.January, 5, 0, 0, 0, 0, time.UTC),
+				EndDate:     time.Date(2023, time.January, 31, 0, 0, 0, 0, time.UTC),
+			},
+			[]entity.SalesPeriod{
+				{
+					Period: "2023-01-08", GrossSales: 22, Fees: 2, NetPayout: 20, Orders: 1, Requests: 1, AverageTicket: 22,
+					Statuses: map[string]int64{"delivered": 1},
+				},
+				{
+					Period: "2023-01-10", GrossSales: 220, Fees: 20, NetPayout: 200, Orders: 1, Requests: 1, AverageTicket: 220,
+					Statuses: map[string]int64{"delivered": 1, "canceled": 1},
+				},
+			},
+			entity.SalesPeriod{
+				GrossSales: 242, Fees: 22, NetPayout: 220, Orders: 2, Requests: 2, AverageTicket: 121,
+				Statuses: map[string]int64{"delivered": 2, "canceled": 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := repo.SalesReport(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("error reporting sales: %v", err)
+			}
+
+			if len(report.Periods) != len(tt.want) {
+				t.Fatalf("periods = %+v, want %+v", report.Periods, tt.want)
+			}
+			for i := range tt.want {
+				assertSalesPeriod(t, report.Periods[i], tt.want[i])
+			}
+			assertSalesPeriod(t, report.Total, tt.total)
+		})
+	}
+}
+
+func TestSalesReportStoreStatuses(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewShop(fixture.DB(t), nil)
+
+	// Stores send their own statuses once a Request is paid.
+	for i, status := range []string{"created", "shipped", "returned to sender", "canceled", "refunded"} {
+		req := fixture.Request(1, 10+i, 2, status)
+		req.PaymentID = strconv.Itoa(i + 1)
+		req.Price = 100
+		req.Tax = 10
+		err := repo.CreateRequest(ctx, &req)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+	}
+
+	report, err := repo.SalesReport(ctx, entity.ReportFilter{StoreID: 1, Period: entity.PeriodMonth})
+	if err != nil {
+		t.Fatalf("error reporting sales: %v", err)
+	}
+
+	want := entity.SalesPeriod{
+		GrossSales: 220, Fees: 20, NetPayout: 200, Orders: 2, Requests: 2, AverageTicket: 110,
+		Statuses: map[string]int64{"shipped": 1, "returned to sender": 1, "canceled": 1, "refunded": 1},
+	}
+	assertSalesPeriod(t, report.Total, want)
+}
+
+func withPeriod(period string, p entity.SalesPeriod) entity.SalesPeriod {
+	p.Period = period
+	return p
+}
+
+func assertSalesPeriod(t *testing.T, got, want entity.SalesPeriod) {
+	t.Helper()
+
+	if got.Period != want.Period || got.GrossSales != want.GrossSales || got.Fees != want.Fees ||
+		got.NetPayout != want.NetPayout || got.Orders != want.Orders || got.Requests != want.Requests ||
+		!approx(got.AverageTicket, want.AverageTicket) || !reflect.DeepEqual(got.Statuses, want.Statuses) {
+		t.Errorf("period = %+v, want %+v", got, want)
+	}
+}
+
+// approx reports whether a and b are equal up to rounding.
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
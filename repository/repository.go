@@ -1,29 +1,240 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"github.com/glebarez/sqlite"
+	"github.com/restore/shop/metrics"
+	"github.com/restore/shop/tracing"
+	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+	"net"
+	"time"
 )
 
-const dsnString = "%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local"
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
 
+	mysqlDSN    = "%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local"
+	postgresDSN = "host=%s port=%s user=%s password=%s dbname=%s sslmode=%s"
+)
+
+// Config describes the database. For SQLite, Database is the file path, or
+// "file::memory:?cache=shared" for an in-process database.
+//
+// Replicas lists the host[:port] of read replicas sharing the credentials of
+// the primary. When set, searches read from a random replica.
 type Config struct {
-	Host     string `yaml:"host"`
-	Port     string `yaml:"port"`
-	User     string `yaml:"user"`
-	Password string `yaml:"password"`
-	Database string `yaml:"database"`
-}
-
-func Init(cfg *Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		dsnString,
-		cfg.User,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.Database,
-	)
-	return gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	Driver   string   `yaml:"driver"`
+	Host     string   `yaml:"host"`
+	Port     string   `yaml:"port"`
+	User     string   `yaml:"user"`
+	Password string   `yaml:"password"`
+	Database string   `yaml:"database"`
+	SSLMode  string   `yaml:"ssl_mode"`
+	Replicas []string `yaml:"replicas"`
+
+	Pool PoolConfig `yaml:"pool"`
+	// ConnectRetry bounds the attempts to connect at startup.
+	ConnectRetry RetryConfig `yaml:"connect_retry"`
+	// Retry bounds the attempts of queries failing with transient errors.
+	Retry RetryConfig `yaml:"retry"`
+}
+
+// PoolConfig describes the connection pool. Zero values keep the defaults
+// of database/sql.
+type PoolConfig struct {
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+}
+
+// Init connects to the database, retrying as set by cfg.ConnectRetry while it
+// isn't up yet.
+func Init(cfg *Config, log *zap.Logger) (*gorm.DB, error) {
+	dialector, err := dialectorFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var db *gorm.DB
+	err = backoff(context.Background(), &cfg.ConnectRetry, func(error) bool { return true }, func(attempt int) error {
+		db, err = gorm.Open(dialector, &gorm.Config{})
+		if err != nil {
+			log.Warn(
+				"error connecting to database",
+				zap.Int("attempt", attempt),
+				zap.Error(err),
+			)
+			closeDB(db)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	setPool(sqlDB, &cfg.Pool)
+
+	if len(cfg.Replicas) > 0 {
+		err = useReplicas(db, cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = observe(db, "metrics", metrics.BeforeDB, metrics.AfterDB)
+	if err != nil {
+		return nil, err
+	}
+
+	err = observe(db, "tracing", tracing.StartDB, tracing.EndDB)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// observe registers before and after around every create, query, update,
+// delete, row and raw operation of db, under name.
+func observe(db *gorm.DB, name string, before, after func(operation string, tx *gorm.DB)) error {
+	cb := db.Callback()
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, hook := range hooks {
+		operation := hook.operation
+		err := hook.before(name+":before_"+operation, func(tx *gorm.DB) {
+			before(operation, tx)
+		})
+		if err != nil {
+			return err
+		}
+		err = hook.after(name+":after_"+operation, func(tx *gorm.DB) {
+			after(operation, tx)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dialectorFor(cfg *Config) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case DriverMySQL, "":
+		return mysql.Open(fmt.Sprintf(
+			mysqlDSN,
+			cfg.User,
+			cfg.Password,
+			cfg.Host,
+			cfg.Port,
+			cfg.Database,
+		)), nil
+	case DriverPostgres:
+		sslMode := cfg.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		return postgres.Open(fmt.Sprintf(
+			postgresDSN,
+			cfg.Host,
+			cfg.Port,
+			cfg.User,
+			cfg.Password,
+			cfg.Database,
+			sslMode,
+		)), nil
+	case DriverSQLite:
+		return sqlite.Open(cfg.Database), nil
+	}
+	return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+}
+
+// useReplicas routes the queries of db to the replicas of cfg. Writes,
+// transactions and queries with the dbresolver.Write clause stay on the
+// primary.
+func useReplicas(db *gorm.DB, cfg *Config) error {
+	replicas := []gorm.Dialector{}
+	for _, address := range cfg.Replicas {
+		replica := *cfg
+		replica.Host, replica.Port = address, cfg.Port
+		if host, port, err := net.SplitHostPort(address); err == nil {
+			replica.Host, replica.Port = host, port
+		}
+
+		dialector, err := dialectorFor(&replica)
+		if err != nil {
+			return err
+		}
+		replicas = append(replicas, dialector)
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	})
+	err := db.Use(resolver)
+	if err != nil {
+		return err
+	}
+
+	return resolver.Call(func(connPool gorm.ConnPool) error {
+		if p, ok := connPool.(pool); ok {
+			setPool(p, &cfg.Pool)
+		}
+		return nil
+	})
+}
+
+type pool interface {
+	SetMaxOpenConns(n int)
+	SetMaxIdleConns(n int)
+	SetConnMaxLifetime(d time.Duration)
+	SetConnMaxIdleTime(d time.Duration)
+}
+
+func setPool(p pool, cfg *PoolConfig) {
+	if cfg.MaxOpenConns > 0 {
+		p.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		p.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		p.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		p.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+}
+
+// closeDB closes the connections left open by a failed gorm.Open.
+func closeDB(db *gorm.DB) {
+	if db == nil || db.ConnPool == nil {
+		return
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
 }
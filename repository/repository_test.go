@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/restore/shop/entity"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"reflect"
+	"testing"
+)
+
+func TestObserve(t *testing.T) {
+	db, err := Init(&Config{Driver: DriverSQLite, Database: "file:observe?mode=memory&cache=shared"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	err = db.AutoMigrate(&entity.Payment{})
+	if err != nil {
+		t.Fatalf("error migrating: %v", err)
+	}
+
+	calls := []string{}
+	err = observe(db, "test", func(operation string, tx *gorm.DB) {
+		calls = append(calls, "before "+operation)
+	}, func(operation string, tx *gorm.DB) {
+		calls = append(calls, "after "+operation)
+	})
+	if err != nil {
+		t.Fatalf("error registering callbacks: %v", err)
+	}
+
+	db.Create(&entity.Payment{Status: "pending"})
+	db.Find(&[]entity.Payment{})
+
+	want := []string{"before create", "after create", "before query", "after query"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"github.com/glebarez/go-sqlite"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	mysqlDeadlock        = 1213
+	mysqlLockWaitTimeout = 1205
+
+	postgresSerializationFailure = "40001"
+	postgresDeadlock             = "40P01"
+	postgresConnectionClass      = "08"
+
+	sqliteBusy   = 5
+	sqliteLocked = 6
+)
+
+type RetryConfig struct {
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+}
+
+// backoff calls fn until it succeeds, retryable reports its error as final
+// or cfg.MaxAttempts calls were made, doubling the wait between calls from
+// InitialBackoff up to MaxBackoff. A nil cfg calls fn once.
+func backoff(ctx context.Context, cfg *RetryConfig, retryable func(error) bool, fn func(attempt int) error) error {
+	attempts, wait := 1, time.Duration(0)
+	if cfg != nil {
+		attempts, wait = cfg.MaxAttempts, cfg.InitialBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || attempt >= attempts || !retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		wait *= 2
+		if cfg.MaxBackoff > 0 && wait > cfg.MaxBackoff {
+			wait = cfg.MaxBackoff
+		}
+	}
+}
+
+// transient reports whether err is a failure worth retrying. Writes are only
+// retried on errors ensuring they were rolled back, like deadlocks, since a
+// broken connection may have lost the reply of an applied write.
+func transient(err error, write bool) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlDeadlock || mysqlErr.Number == mysqlLockWaitTimeout
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case postgresSerializationFailure, postgresDeadlock:
+			return true
+		}
+		return !write && strings.HasPrefix(pgErr.Code, postgresConnectionClass)
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		code := sqliteErr.Code() & 0xff
+		return code == sqliteBusy || code == sqliteLocked
+	}
+
+	if write {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.As(err, &netErr)
+}
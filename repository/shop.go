@@ -2,167 +2,344 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/restore/shop/entity"
+	"github.com/restore/shop/metrics"
 	"gorm.io/gorm"
-	"time"
+	"gorm.io/plugin/dbresolver"
 )
 
 type Shop struct {
-	db *gorm.DB
+	db    *gorm.DB
+	retry *RetryConfig
 }
 
-func NewShop(db *gorm.DB) *Shop {
+// NewShop builds the repository of the shop. Queries failing with transient
+// errors are retried as set by retry; a nil retry never retries.
+func NewShop(db *gorm.DB, retry *RetryConfig) *Shop {
 	return &Shop{
-		db: db,
+		db:    db,
+		retry: retry,
 	}
 }
 
-func (s *Shop) CreateRequest(ctx context.Context, request *entity.Request) error {
-	result := s.db.Create(request)
-	if result.Error != nil {
-		return result.Error
+// primary returns the database for reads that must see the latest writes,
+// bypassing the read replicas.
+func (s *Shop) primary(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Clauses(dbresolver.Write)
+}
+
+// withRetry runs fn again while it fails with a transient error. Its final
+// error is marked as an entity.ErrStorage unless it is about the call, like a
+// missing record or a version conflict.
+func (s *Shop) withRetry(ctx context.Context, name string, write bool, fn func() error) error {
+	err := backoff(ctx, s.retry, func(err error) bool {
+		if !transient(err, write) {
+			return false
+		}
+		metrics.DBRetried(name)
+		return true
+	}, func(int) error {
+		return fn()
+	})
+
+	switch {
+	case err == nil,
+		errors.Is(err, gorm.ErrRecordNotFound),
+		errors.Is(err, entity.ErrVersionConflict),
+		errors.Is(err, ErrInvalidCursor),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return err
 	}
-	return nil
+	return entity.StorageError(err)
+}
+
+func (s *Shop) CreateRequest(ctx context.Context, request *entity.Request) error {
+	request.Version = 1
+
+	return s.withRetry(ctx, "CreateRequest", true, func() error {
+		result := s.db.WithContext(ctx).Create(request)
+		if result.Error != nil {
+			return result.Error
+		}
+		return nil
+	})
 }
 
+// UpdateRequest updates the status and track of a Request, if its version
+// is still request.Version. A zero request.Version updates any version. On
+// success request.Version is the new version.
 func (s *Shop) UpdateRequest(ctx context.Context, id int, request *entity.Request) error {
-	result := entity.Request{ID: id}
-	res := s.db.First(&result)
-	if res.Error != nil {
-		return res.Error
+	return s.withRetry(ctx, "UpdateRequest", true, func() error {
+		result := entity.Request{ID: id}
+		res := s.primary(ctx).First(&result)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		version, err := expectedVersion(request.Version, result.Version)
+		if err != nil {
+			return err
+		}
+
+		err = updateRequest(s.db.WithContext(ctx), id, version, request)
+		if err != nil {
+			return err
+		}
+
+		request.Version = version + 1
+		return nil
+	})
+}
+
+// UpdateRequests updates the status and track of every request in a single
+// transaction, if their versions are still the given ones. Either all or
+// none are updated. On success the Version of each request is its new one.
+func (s *Shop) UpdateRequests(ctx context.Context, requests []entity.Request) error {
+	err := s.withRetry(ctx, "UpdateRequests", true, func() error {
+		return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for i := range requests {
+				err := updateRequest(tx, requests[i].ID, requests[i].Version, &requests[i])
+				if err != nil {
+					return fmt.Errorf("request %d: %w", requests[i].ID, err)
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
 	}
 
-	result.Status = request.Status
-	result.Track = request.Track
+	for i := range requests {
+		requests[i].Version++
+	}
+	return nil
+}
 
-	res = s.db.Save(result)
+// updateRequest updates the status and track of the Request id, if its
+// version is still version.
+func updateRequest(db *gorm.DB, id, version int, request *entity.Request) error {
+	res := db.Model(&entity.Request{}).
+		Where("id = ? AND version = ?", id, version).
+		Updates(map[string]interface{}{
+			"status":  request.Status,
+			"track":   request.Track,
+			"version": gorm.Expr("version + 1"),
+		})
 	if res.Error != nil {
 		return res.Error
 	}
+	if res.RowsAffected == 0 {
+		return entity.ErrVersionConflict
+	}
 	return nil
 }
 
 func (s *Shop) ConfirmRequests(ctx context.Context, paymentID string) error {
-	res := s.db.Model(&entity.Request{}).
-		Where("payment_id = ?", paymentID).
-		Update("status", "preparing")
-	if res.Error != nil {
-		return res.Error
-	}
+	return s.withRetry(ctx, "ConfirmRequests", true, func() error {
+		res := s.db.WithContext(ctx).Model(&entity.Request{}).
+			Where("payment_id = ?", paymentID).
+			Updates(map[string]interface{}{
+				"status":  "preparing",
+				"version": gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (s *Shop) GetRequestByPayment(ctx context.Context, paymentID string) ([]entity.Request, error) {
 	var result []entity.Request
-	query := s.db.Where("payment_id = ?", paymentID)
-	res := query.Find(&result)
-	if res.Error != nil {
-		return nil, res.Error
+	err := s.withRetry(ctx, "GetRequestByPayment", false, func() error {
+		query := s.primary(ctx).Where("payment_id = ?", paymentID)
+		res := query.Find(&result)
+		return res.Error
+	})
+	if err != nil {
+		return nil, err
 	}
 	return result, nil
 }
 
-func (s *Shop) SearchRequest(ctx context.Context, id int, status string, init, end time.Time) ([]entity.Request, error) {
+// GetRequests gets the Requests of ids, from the primary.
+func (s *Shop) GetRequests(ctx context.Context, ids []int) ([]entity.Request, error) {
 	var result []entity.Request
-	query := s.db.Where("store_id = ? AND status != ?", id, "created")
-
-	if status != "" {
-		query.Where("status = ?", status)
-	}
-
-	t := time.Time{}
-	if init != t {
-		query.Where("created_at > ?", init)
-	}
-	if end != t {
-		query.Where("created_at < ?", end)
+	err := s.withRetry(ctx, "GetRequests", false, func() error {
+		res := s.primary(ctx).Where("id IN ?", ids).Find(&result)
+		return res.Error
+	})
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	res := query.Find(&result)
-	if res.Error != nil {
-		return nil, res.Error
+// GetRequest gets a Request from the primary, so its version reflects the
+// latest update.
+func (s *Shop) GetRequest(ctx context.Context, id int) (*entity.Request, error) {
+	result := entity.Request{ID: id}
+	err := s.withRetry(ctx, "GetRequest", false, func() error {
+		return s.primary(ctx).First(&result).Error
+	})
+	if err != nil {
+		return nil, err
 	}
-	return result, nil
+	return &result, nil
 }
 
-func (s *Shop) SearchProfileRequest(ctx context.Context, id int, status string, init, end time.Time) ([]entity.Request, error) {
-	var result []entity.Request
-	query := s.db.Where("user_id = ? AND status != ?", id, "created")
+func (s *Shop) SearchRequest(ctx context.Context, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error) {
+	var (
+		result []entity.Request
+		info   entity.PageInfo
+	)
+	err := s.withRetry(ctx, "SearchRequest", false, func() error {
+		query := filterRequests(s.db.WithContext(ctx).Model(&entity.Request{}), filter)
 
-	if status != "" {
-		query.Where("status = ?", status)
-	}
+		var err error
+		result, info, err = paginate(query, page, requestKey)
+		return err
+	})
+	return result, info, err
+}
 
-	t := time.Time{}
-	if init != t {
-		query.Where("created_at > ?", init)
-	}
-	if end != t {
-		query.Where("created_at < ?", end)
-	}
+// ListRequests returns the page of Requests matching filter and the cursor
+// of the next one, without counting them, for exports walking every page.
+func (s *Shop) ListRequests(ctx context.Context, filter entity.RequestFilter, page entity.Page) ([]entity.Request, string, error) {
+	var (
+		result []entity.Request
+		next   string
+	)
+	err := s.withRetry(ctx, "ListRequests", false, func() error {
+		query := filterRequests(s.db.WithContext(ctx).Model(&entity.Request{}), filter)
 
-	res := query.Find(&result)
-	if res.Error != nil {
-		return nil, res.Error
-	}
-	return result, nil
+		var err error
+		result, next, err = seek(query, page, requestKey)
+		return err
+	})
+	return result, next, err
 }
 
 func (s *Shop) CreatePayment(ctx context.Context, payment *entity.Payment) (int, error) {
-	result := s.db.Create(payment)
-	if result.Error != nil {
-		return 0, result.Error
+	payment.Version = 1
+
+	err := s.withRetry(ctx, "CreatePayment", true, func() error {
+		result := s.db.WithContext(ctx).Create(payment)
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
 	}
 	return payment.ID, nil
 }
 
+// UpdatePayment updates the status of a Payment, if its version is still
+// payment.Version. A zero payment.Version updates any version. On success
+// payment.Version is the new version.
 func (s *Shop) UpdatePayment(ctx context.Context, id int, payment *entity.Payment) error {
-	result := entity.Payment{ID: id}
-	res := s.db.First(&result)
-	if res.Error != nil {
-		return res.Error
-	}
+	return s.withRetry(ctx, "UpdatePayment", true, func() error {
+		result := entity.Payment{ID: id}
+		res := s.primary(ctx).First(&result)
+		if res.Error != nil {
+			return res.Error
+		}
 
-	result.Status = payment.Status
+		version, err := expectedVersion(payment.Version, result.Version)
+		if err != nil {
+			return err
+		}
 
-	res = s.db.Save(result)
-	if res.Error != nil {
-		return res.Error
-	}
-	return nil
+		res = s.db.WithContext(ctx).Model(&entity.Payment{}).
+			Where("id = ? AND version = ?", id, version).
+			Updates(map[string]interface{}{
+				"status":  payment.Status,
+				"version": gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return entity.ErrVersionConflict
+		}
+
+		payment.Version = version + 1
+		return nil
+	})
 }
 
-func (s *Shop) GetPayments(ctx context.Context, id int) ([]entity.Payment, error) {
-	var result []entity.Payment
-	query := s.db.Where("store_id = ?", id)
+// expectedVersion returns the version an update must find: expected, or the
+// current one when expected is zero.
+func expectedVersion(expected, current int) (int, error) {
+	if expected == 0 {
+		return current, nil
+	}
+	if expected != current {
+		return 0, entity.ErrVersionConflict
+	}
+	return expected, nil
+}
 
-	res := query.Find(&result)
-	if res.Error != nil {
-		return nil, res.Error
+// GetPayment gets a Payment from the primary, so its version reflects the
+// latest update.
+func (s *Shop) GetPayment(ctx context.Context, id int) (*entity.Payment, error) {
+	result := entity.Payment{ID: id}
+	err := s.withRetry(ctx, "GetPayment", false, func() error {
+		return s.primary(ctx).First(&result).Error
+	})
+	if err != nil {
+		return nil, err
 	}
-	return result, nil
+	return &result, nil
 }
 
-func (s *Shop) SearchPayment(ctx context.Context, status string, init, end time.Time) ([]entity.Payment, error) {
-	var result []entity.Payment
-	query := s.db.Where("")
+func (s *Shop) GetPayments(ctx context.Context, id int, page entity.Page) ([]entity.Payment, entity.PageInfo, error) {
+	var (
+		result []entity.Payment
+		info   entity.PageInfo
+	)
+	err := s.withRetry(ctx, "GetPayments", false, func() error {
+		query := s.db.WithContext(ctx).Where("store_id = ?", id)
 
-	if status != "" {
-		query.Where("status = ?", status)
-	}
+		var err error
+		result, info, err = paginate(query, page, paymentKey)
+		return err
+	})
+	return result, info, err
+}
 
-	t := time.Time{}
-	if init != t {
-		query.Where("created_at > ?", init)
-	}
-	if end != t {
-		query.Where("created_at < ?", end)
-	}
+func (s *Shop) SearchPayment(ctx context.Context, filter entity.PaymentFilter, page entity.Page) ([]entity.Payment, entity.PageInfo, error) {
+	var (
+		result []entity.Payment
+		info   entity.PageInfo
+	)
+	err := s.withRetry(ctx, "SearchPayment", false, func() error {
+		query := filterPayments(s.db.WithContext(ctx).Model(&entity.Payment{}), filter)
 
-	res := query.Find(&result)
-	if res.Error != nil {
-		return nil, res.Error
-	}
-	return result, nil
+		var err error
+		result, info, err = paginate(query, page, paymentKey)
+		return err
+	})
+	return result, info, err
+}
+
+// ListPayments returns the page of Payments matching filter and the cursor
+// of the next one, without counting them, for exports walking every page.
+func (s *Shop) ListPayments(ctx context.Context, filter entity.PaymentFilter, page entity.Page) ([]entity.Payment, string, error) {
+	var (
+		result []entity.Payment
+		next   string
+	)
+	err := s.withRetry(ctx, "ListPayments", false, func() error {
+		query := filterPayments(s.db.WithContext(ctx).Model(&entity.Payment{}), filter)
+
+		var err error
+		result, next, err = seek(query, page, paymentKey)
+		return err
+	})
+	return result, next, err
 }
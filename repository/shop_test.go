@@ -0,0 +1,314 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/fixture"
+	"github.com/restore/shop/repository"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateRequest(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewShop(fixture.DB(t), nil)
+
+	req := fixture.Request(1, 10, 2, "created")
+	req.Snapshot = entity.ProductSnapshot{
+		Name:      "Product 10",
+		Price:     100,
+		Available: true,
+		Images:    []entity.Image{{ID: 1, ImagePath: "images/10.png", ProductID: 10}},
+	}
+	err := repo.CreateRequest(ctx, &req)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	if req.ID == 0 || req.Version != 1 {
+		t.Fatalf("request = %+v, want an ID at version 1", req)
+	}
+
+	stored, err := repo.GetRequests(ctx, []int{req.ID})
+	if err != nil {
+		t.Fatalf("error getting request: %v", err)
+	}
+	if len(stored) != 1 {
+		t.Fatalf("requests = %d, want 1", len(stored))
+	}
+	snapshot := stored[0].Snapshot
+	if snapshot.Name != "Product 10" || !snapshot.Available || len(snapshot.Images) != 1 || snapshot.Images[0].ImagePath != "images/10.png" {
+		t.Errorf("snapshot = %+v, want the stored one", snapshot)
+	}
+}
+
+func TestUpdateRequest(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewShop(fixture.DB(t), nil)
+
+	req := fixture.Request(1, 10, 2, "created")
+	err := repo.CreateRequest(ctx, &req)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	update := &entity.Request{Status: "preparing"}
+	err = repo.UpdateRequest(ctx, req.ID, update)
+	if err != nil {
+		t.Fatalf("error updating any version: %v", err)
+	}
+	if update.Version != 2 {
+		t.Errorf("version = %d, want 2", update.Version)
+	}
+
+	update = &entity.Request{Status: "sent", Track: "BR123", Version: 2}
+	err = repo.UpdateRequest(ctx, req.ID, update)
+	if err != nil {
+		t.Fatalf("error updating version 2: %v", err)
+	}
+
+	err = repo.UpdateRequest(ctx, req.ID, &entity.Request{Status: "canceled", Version: 2})
+	if !errors.Is(err, entity.ErrVersionConflict) {
+		t.Errorf("error = %v, want %v", err, entity.ErrVersionConflict)
+	}
+
+	err = repo.UpdateRequest(ctx, req.ID+1, &entity.Request{Status: "sent"})
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+
+	stored, err := repo.GetRequest(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("error getting request: %v", err)
+	}
+	if stored.Status != "sent" || stored.Track != "BR123" || stored.Version != 3 {
+		t.Errorf("request = %+v, want sent with track at version 3", stored)
+	}
+}
+
+func TestUpdateRequestsRollsBack(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewShop(fixture.DB(t), nil)
+
+	for i := 0; i < 2; i++ {
+		req := fixture.Request(1, 10+i, 2, "preparing")
+		err := repo.CreateRequest(ctx, &req)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+	}
+
+	err := repo.UpdateRequests(ctx, []entity.Request{
+		{ID: 1, Status: "sent", Version: 1},
+		{ID: 2, Status: "sent", Version: 5},
+	})
+	if !errors.Is(err, entity.ErrVersionConflict) {
+		t.Fatalf("error = %v, want %v", err, entity.ErrVersionConflict)
+	}
+
+	stored, err := repo.GetRequests(ctx, []int{1, 2})
+	if err != nil {
+		t.Fatalf("error getting requests: %v", err)
+	}
+	for _, req := range stored {
+		if req.Status != "preparing" || req.Version != 1 {
+			t.Errorf("request = %+v, want it untouched", req)
+		}
+	}
+
+	updates := []entity.Request{
+		{ID: 1, Status: "sent", Version: 1},
+		{ID: 2, Status: "sent", Version: 1},
+	}
+	err = repo.UpdateRequests(ctx, updates)
+	if err != nil {
+		t.Fatalf("error updating requests: %v", err)
+	}
+	if updates[0].Version != 2 || updates[1].Version != 2 {
+		t.Errorf("versions = %d %d, want 2 2", updates[0].Version, updates[1].Version)
+	}
+}
+
+func TestConfirmRequests(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewShop(fixture.DB(t), nil)
+
+	for _, paymentID := range []string{"1", "1", "2"} {
+		req := fixture.Request(1, 10, 2, "created")
+		req.PaymentID = paymentID
+		err := repo.CreateRequest(ctx, &req)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+	}
+
+	err := repo.ConfirmRequests(ctx, "1")
+	if err != nil {
+		t.Fatalf("error confirming requests: %v", err)
+	}
+
+	confirmed, err := repo.GetRequestByPayment(ctx, "1")
+	if err != nil {
+		t.Fatalf("error getting requests: %v", err)
+	}
+	if len(confirmed) != 2 {
+		t.Fatalf("requests = %d, want 2", len(confirmed))
+	}
+	for _, req := range confirmed {
+		if req.Status != "preparing" || req.Version != 2 {
+			t.Errorf("request = %+v, want preparing at version 2", req)
+		}
+	}
+
+	other, err := repo.GetRequestByPayment(ctx, "2")
+	if err != nil {
+		t.Fatalf("error getting requests: %v", err)
+	}
+	if other[0].Status != "created" {
+		t.Errorf("status = %q, want created", other[0].Status)
+	}
+}
+
+func TestSearchRequestPages(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewShop(fixture.DB(t), nil)
+
+	for i := 0; i < 5; i++ {
+		req := fixture.Request(1, 10, 2, "preparing")
+		// Two requests share each creation date, ordered by ID.
+		req.CreatedAt = fixture.CreatedAt.Add(time.Duration(i/2) * time.Hour)
+		err := repo.CreateRequest(ctx, &req)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+	}
+
+	tests := []struct {
+		sort string
+		want []int
+	}{
+		{entity.SortDesc, []int{5, 4, 3, 2, 1}},
+		{entity.SortAsc, []int{1, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			ids := []int{}
+			page := entity.Page{Limit: 2, Sort: tt.sort}
+			for {
+				result, info, err := repo.SearchRequest(ctx, entity.RequestFilter{}, page)
+				if err != nil {
+					t.Fatalf("error searching requests: %v", err)
+				}
+				if info.Total != 5 {
+					t.Errorf("total = %d, want 5", info.Total)
+				}
+				for _, req := range result {
+					ids = append(ids, req.ID)
+				}
+				if info.NextCursor == "" {
+					break
+				}
+				page.Cursor = info.NextCursor
+			}
+			assertIDs(t, ids, tt.want)
+		})
+	}
+
+	_, _, err := repo.SearchRequest(ctx, entity.RequestFilter{}, entity.Page{Cursor: "not a cursor"})
+	if !errors.Is(err, repository.ErrInvalidCursor) {
+		t.Errorf("error = %v, want %v", err, repository.ErrInvalidCursor)
+	}
+}
+
+func TestUpdatePayment(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewShop(fixture.DB(t), nil)
+
+	payment := fixture.Payment(1, 10, 100, "pending")
+	id, err := repo.CreatePayment(ctx, &payment)
+	if err != nil {
+		t.Fatalf("error creating payment: %v", err)
+	}
+
+	update := &entity.Payment{Status: "paid", Version: 1}
+	err = repo.UpdatePayment(ctx, id, update)
+	if err != nil {
+		t.Fatalf("error updating payment: %v", err)
+	}
+	if update.Version != 2 {
+		t.Errorf("version = %d, want 2", update.Version)
+	}
+
+	err = repo.UpdatePayment(ctx, id, &entity.Payment{Status: "canceled", Version: 1})
+	if !errors.Is(err, entity.ErrVersionConflict) {
+		t.Errorf("error = %v, want %v", err, entity.ErrVersionConflict)
+	}
+
+	result, _, err := repo.GetPayments(ctx, 1, entity.Page{})
+	if err != nil {
+		t.Fatalf("error getting payments: %v", err)
+	}
+	if len(result) != 1 || result[0].Status != "paid" {
+		t.Errorf("payments = %+v, want one paid", result)
+	}
+
+	stored, err := repo.GetPayment(ctx, id)
+	if err != nil {
+		t.Fatalf("error getting payment: %v", err)
+	}
+	if stored.Status != "paid" || stored.Version != 2 {
+		t.Errorf("payment = %+v, want paid at version 2", stored)
+	}
+
+	_, err = repo.GetPayment(ctx, id+1)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+}
+
+func TestListRequestsWithoutCount(t *testing.T) {
+	ctx := context.Background()
+	db := fixture.DB(t)
+	repo := repository.NewShop(db, nil)
+
+	for i := 0; i < 5; i++ {
+		req := fixture.Request(1+i%2, 10, 2, "preparing")
+		req.CreatedAt = fixture.CreatedAt.Add(time.Duration(i/2) * time.Hour)
+		err := repo.CreateRequest(ctx, &req)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+	}
+
+	counts := 0
+	err := db.Callback().Query().After("gorm:query").Register("test:count", func(tx *gorm.DB) {
+		if strings.Contains(strings.ToLower(tx.Statement.SQL.String()), "count(") {
+			counts++
+		}
+	})
+	if err != nil {
+		t.Fatalf("error registering callback: %v", err)
+	}
+
+	ids := []int{}
+	page := entity.Page{Limit: 2, Sort: entity.SortAsc}
+	for {
+		result, next, err := repo.ListRequests(ctx, entity.RequestFilter{StoreID: 1}, page)
+		if err != nil {
+			t.Fatalf("error listing requests: %v", err)
+		}
+		for _, req := range result {
+			ids = append(ids, req.ID)
+		}
+		if next == "" {
+			break
+		}
+		page.Cursor = next
+	}
+	assertIDs(t, ids, []int{1, 3, 5})
+	if counts != 0 {
+		t.Errorf("counts = %d, want none", counts)
+	}
+}
@@ -5,7 +5,6 @@ docker run -it --rm -v "$(pwd)":/go -e GOPATH= golang:1.21 sh -c "CGO_ENABLED=0
 
 rm ./docker/shop
 mv ./shop ./docker/
-cp ./docker-config.yaml ./docker/config.yaml
 
 docker build -t restore/shop:"$1" docker/
 
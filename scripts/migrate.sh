@@ -1,7 +1,24 @@
 #!/bin/bash
 
-cont=$(docker ps | grep mysql | awk '{print $1}')
+# Runs the migrate subcommand of the shop container:
+#
+#   make migrate cmd=up
+#   make migrate cmd="down 1"
+#   make migrate cmd=status
+#
+# Databases migrated by hand with the old numbered scripts 0.sql and 1.sql
+# (make migrate number=N) must record them once as applied before the first
+# "up", or it fails on their existing tables:
+#
+#   make migrate cmd="force 2"
+#
+# force records the given version and every earlier one without running them.
 
-docker cp "$(pwd)"/migrations "$cont":/
+if [ -n "$number" ]; then
+  echo "the numbered scripts were replaced by versioned migrations, see $0" >&2
+  exit 1
+fi
 
-docker exec -it "$cont" bash -c "mysql < /migrations/$1.sql"
\ No newline at end of file
+cont=$(docker ps | grep rs_shop | awk '{print $1}')
+
+docker exec -it "$cont" /go/src/shop migrate "$@"
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/restore/shop/entity"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// toStatus maps an error of the controller to the gRPC status telling the
+// caller whether to fix the call, authenticate, or retry later.
+func toStatus(err error) error {
+	code := codes.InvalidArgument
+	switch {
+	case errors.Is(err, entity.ErrUnauthenticated):
+		code = codes.Unauthenticated
+	case errors.Is(err, entity.ErrUnauthorized):
+		code = codes.PermissionDenied
+	case errors.Is(err, entity.ErrRequestNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		code = codes.NotFound
+	case errors.Is(err, entity.ErrVersionConflict):
+		code = codes.Aborted
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, entity.ErrStorage):
+		code = codes.Internal
+	default:
+		// Errors of the upstream services carry their own status.
+		if s, ok := status.FromError(err); ok {
+			code = upstreamCode(s.Code())
+		}
+	}
+	return status.Error(code, err.Error())
+}
+
+// upstreamCode maps the status of a failed upstream call to the one returned
+// by the shop.
+func upstreamCode(code codes.Code) codes.Code {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return codes.Unavailable
+	case codes.NotFound, codes.InvalidArgument, codes.FailedPrecondition:
+		return code
+	}
+	return codes.Internal
+}
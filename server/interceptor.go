@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"github.com/restore/shop/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// RequestID propagates the request ID of the incoming metadata, or a new
+// one, into the context.
+func RequestID(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	id := ""
+	md, ok := metadata.FromIncomingContext(ctx)
+	if ok {
+		if values := md.Get(logger.RequestIDKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		id = logger.NewRequestID()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(logger.RequestIDKey, id))
+	return handler(logger.WithRequestID(ctx, id), req)
+}
+
+// Logger logs every call handled by the server.
+func Logger(log *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		fields := []zap.Field{
+			zap.String("method", info.FullMethod),
+			zap.String("code", status.Code(err).String()),
+			zap.Duration("latency", time.Since(start)),
+		}
+		if err != nil {
+			logger.For(ctx, log).Warn("call", append(fields, zap.Error(err))...)
+		} else {
+			logger.For(ctx, log).Info("call", fields...)
+		}
+		return resp, err
+	}
+}
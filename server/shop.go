@@ -0,0 +1,223 @@
+package server
+
+import (
+	"context"
+	"github.com/restore/shop/config"
+	"github.com/restore/shop/entity"
+	shoppb "github.com/restore/shop/proto/shop"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strconv"
+	"strings"
+)
+
+type controller interface {
+	CreateRequest(ctx context.Context, request *entity.Create) (string, error)
+	ConfirmRequest(ctx context.Context, paymentID string) error
+	GetPaymentRequests(ctx context.Context, paymentID string) ([]entity.Request, error)
+	SearchAllRequest(ctx context.Context, filter entity.RequestFilter, page entity.Page) ([]entity.Request, entity.PageInfo, error)
+
+	GetPayments(ctx context.Context, storeID string, page entity.Page) ([]entity.Payment, entity.PageInfo, error)
+}
+
+// Shop serves the shop over gRPC.
+type Shop struct {
+	shoppb.UnimplementedShopServer
+
+	controller controller
+}
+
+func NewShop(c controller) *Shop {
+	return &Shop{
+		controller: c,
+	}
+}
+
+// CreateOrder creates the Requests of an order and its payment.
+func (s *Shop) CreateOrder(ctx context.Context, in *shoppb.CreateOrderRequest) (*shoppb.CreateOrderResponse, error) {
+	ctx = withEmail(ctx)
+
+	var request entity.Create
+	for _, item := range in.Items {
+		request.Items = append(request.Items, entity.Request{
+			Price:     item.Price,
+			Tax:       item.Tax,
+			StoreID:   int(item.StoreId),
+			ProductID: int(item.ProductId),
+			UserID:    int(item.UserId),
+		})
+	}
+
+	id, err := s.controller.CreateRequest(ctx, &request)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &shoppb.CreateOrderResponse{PaymentId: id}, nil
+}
+
+// ConfirmPayment confirms the Requests of a payment.
+func (s *Shop) ConfirmPayment(ctx context.Context, in *shoppb.ConfirmPaymentRequest) (*shoppb.ConfirmPaymentResponse, error) {
+	ctx = withEmail(ctx)
+
+	if in.PaymentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid ID")
+	}
+
+	err := s.controller.ConfirmRequest(ctx, in.PaymentId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &shoppb.ConfirmPaymentResponse{}, nil
+}
+
+// GetRequests gets the Requests of a payment.
+func (s *Shop) GetRequests(ctx context.Context, in *shoppb.GetRequestsRequest) (*shoppb.GetRequestsResponse, error) {
+	ctx = withEmail(ctx)
+
+	if in.PaymentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid ID")
+	}
+
+	result, err := s.controller.GetPaymentRequests(ctx, in.PaymentId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &shoppb.GetRequestsResponse{Requests: toRequests(result)}, nil
+}
+
+// SearchRequests searches for Requests.
+func (s *Shop) SearchRequests(ctx context.Context, in *shoppb.SearchRequestsRequest) (*shoppb.SearchRequestsResponse, error) {
+	ctx = withEmail(ctx)
+
+	filter := entity.RequestFilter{
+		StoreID:   int(in.StoreId),
+		UserID:    int(in.UserId),
+		ProductID: int(in.ProductId),
+		PaymentID: in.PaymentId,
+		Status:    in.Status,
+		Refresh:   in.Refresh,
+	}
+	if in.InitialDate != nil {
+		filter.InitialDate = in.InitialDate.AsTime()
+	}
+	if in.EndDate != nil {
+		filter.EndDate = in.EndDate.AsTime()
+	}
+
+	result, info, err := s.controller.SearchAllRequest(ctx, filter, toPage(in.Page))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &shoppb.SearchRequestsResponse{
+		Requests:   toRequests(result),
+		Total:      info.Total,
+		NextCursor: info.NextCursor,
+	}, nil
+}
+
+// GetStorePayments searches for Payments of a store.
+func (s *Shop) GetStorePayments(ctx context.Context, in *shoppb.GetStorePaymentsRequest) (*shoppb.GetStorePaymentsResponse, error) {
+	ctx = withEmail(ctx)
+
+	result, info, err := s.controller.GetPayments(ctx, strconv.Itoa(int(in.StoreId)), toPage(in.Page))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	payments := []*shoppb.Payment{}
+	for _, payment := range result {
+		payments = append(payments, &shoppb.Payment{
+			Id:        int32(payment.ID),
+			Total:     payment.Total,
+			Pix:       payment.PIX,
+			Status:    payment.Status,
+			CreatedAt: timestamppb.New(payment.CreatedAt),
+			StoreId:   int32(payment.StoreID),
+			ProductId: int32(payment.ProductID),
+		})
+	}
+
+	return &shoppb.GetStorePaymentsResponse{
+		Payments:   payments,
+		Total:      info.Total,
+		NextCursor: info.NextCursor,
+	}, nil
+}
+
+// withEmail copies the caller email from the incoming metadata, the same way
+// the HTTP handlers copy it from the request header.
+func withEmail(ctx context.Context) context.Context {
+	email := ""
+	md, ok := metadata.FromIncomingContext(ctx)
+	if ok {
+		if values := md.Get(strings.ToLower(config.EmailHeader)); len(values) > 0 {
+			email = values[0]
+		}
+	}
+	return context.WithValue(ctx, config.EmailHeader, email)
+}
+
+func toPage(page *shoppb.Page) entity.Page {
+	if page == nil {
+		return entity.Page{}
+	}
+	return entity.Page{
+		Limit:  int(page.Limit),
+		Cursor: page.Cursor,
+		Sort:   page.Sort,
+	}
+}
+
+func toRequests(requests []entity.Request) []*shoppb.Request {
+	result := []*shoppb.Request{}
+	for _, req := range requests {
+		result = append(result, &shoppb.Request{
+			Id:        int32(req.ID),
+			PaymentId: req.PaymentID,
+			Price:     req.Price,
+			Tax:       req.Tax,
+			Track:     req.Track,
+			Status:    req.Status,
+			CreatedAt: timestamppb.New(req.CreatedAt),
+			StoreId:   int32(req.StoreID),
+			ProductId: int32(req.ProductID),
+			UserId:    int32(req.UserID),
+			Product:   toProduct(req.Product),
+			Warning:   req.Warning,
+		})
+	}
+	return result
+}
+
+func toProduct(product *entity.Product) *shoppb.Product {
+	if product == nil {
+		return nil
+	}
+
+	imgs := []*shoppb.Image{}
+	for _, img := range product.Images {
+		imgs = append(imgs, &shoppb.Image{
+			Id:        int32(img.ID),
+			ImagePath: img.ImagePath,
+			ProductId: int32(img.ProductID),
+		})
+	}
+	return &shoppb.Product{
+		Id:          int32(product.ID),
+		Name:        product.Name,
+		Description: product.Description,
+		Categories:  product.Categories,
+		Size:        product.Size,
+		Price:       product.Price,
+		Tax:         product.Tax,
+		Available:   product.Available,
+		StoreId:     int32(product.StoreID),
+		Images:      imgs,
+	}
+}
@@ -0,0 +1,100 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"github.com/restore/shop/config"
+	"github.com/restore/shop/entity"
+	"github.com/restore/shop/fixture"
+	shoppb "github.com/restore/shop/proto/shop"
+	"github.com/restore/shop/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strconv"
+	"testing"
+)
+
+// incoming returns the context of a gRPC call made by email.
+func incoming(email string) context.Context {
+	md := metadata.MD{}
+	if email != "" {
+		md.Set(config.EmailHeader, email)
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestGetRequestsAuthorization(t *testing.T) {
+	shop := fixture.NewShop()
+	buyer, _ := strconv.Atoi(fixture.UserID)
+	req := fixture.Request(1, 10, buyer, "preparing")
+	err := shop.Repository.CreateRequest(context.Background(), &req)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	srv := server.NewShop(shop)
+
+	tests := []struct {
+		name  string
+		email string
+		want  codes.Code
+	}{
+		{"admin", fixture.AdminEmail, codes.OK},
+		{"buyer", fixture.UserEmail, codes.OK},
+		{"other user", "other@restore.test", codes.Unauthenticated},
+		{"no caller", "", codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := srv.GetRequests(incoming(tt.email), &shoppb.GetRequestsRequest{PaymentId: req.PaymentID})
+			if code := status.Code(err); code != tt.want {
+				t.Errorf("code = %s, want %s (%v)", code, tt.want, err)
+			}
+		})
+	}
+
+	t.Run("other buyer", func(t *testing.T) {
+		shop.Users.Users["other@restore.test"] = fixture.Users()[fixture.UserEmail]
+		shop.Users.Users["other@restore.test"].Id = "3"
+
+		_, err := srv.GetRequests(incoming("other@restore.test"), &shoppb.GetRequestsRequest{PaymentId: req.PaymentID})
+		if code := status.Code(err); code != codes.PermissionDenied {
+			t.Errorf("code = %s, want %s (%v)", code, codes.PermissionDenied, err)
+		}
+	})
+}
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		setup func(shop *fixture.Shop)
+		want  codes.Code
+	}{
+		{"not admin", fixture.UserEmail, func(*fixture.Shop) {}, codes.PermissionDenied},
+		{"unknown caller", "nobody@restore.test", func(*fixture.Shop) {}, codes.Unauthenticated},
+		{"user service down", fixture.AdminEmail, func(shop *fixture.Shop) {
+			shop.Users.Err = status.Error(codes.Unavailable, "connection refused")
+		}, codes.Unavailable},
+		{"database error", fixture.AdminEmail, func(shop *fixture.Shop) {
+			shop.Repository.Err = entity.StorageError(errors.New("connection reset"))
+		}, codes.Internal},
+		{"invalid page", fixture.AdminEmail, func(*fixture.Shop) {}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shop := fixture.NewShop()
+			tt.setup(shop)
+			srv := server.NewShop(shop)
+
+			in := &shoppb.GetStorePaymentsRequest{StoreId: 1}
+			if tt.want == codes.InvalidArgument {
+				in.Page = &shoppb.Page{Sort: "sideways"}
+			}
+			_, err := srv.GetStorePayments(incoming(tt.email), in)
+			if code := status.Code(err); code != tt.want {
+				t.Errorf("code = %s, want %s (%v)", code, tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package stub
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+)
+
+// Seed represents the data served by the stub upstreams.
+type Seed struct {
+	Users    []User    `yaml:"users"`
+	Products []Product `yaml:"products"`
+	Payments []Payment `yaml:"payments"`
+}
+
+// User represents a user of the stub user service.
+type User struct {
+	ID    string `yaml:"id"`
+	Email string `yaml:"email"`
+	Admin bool   `yaml:"admin"`
+}
+
+// Product represents a product of the stub product service. Products are
+// available unless Available is false.
+type Product struct {
+	ID          int      `yaml:"id"`
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Categories  string   `yaml:"categories"`
+	Size        string   `yaml:"size"`
+	Price       float32  `yaml:"price"`
+	Tax         float32  `yaml:"tax"`
+	Available   *bool    `yaml:"available"`
+	StoreID     int      `yaml:"store_id"`
+	Images      []string `yaml:"images"`
+}
+
+// Payment represents a payment of the stub payment service. Payments are
+// handed out in order to the created orders.
+type Payment struct {
+	ID string `yaml:"id"`
+}
+
+// LoadSeed reads the Seed in the YAML file at path.
+func LoadSeed(path string) (*Seed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	seed := &Seed{}
+	err = yaml.NewDecoder(f).Decode(seed)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("error decoding %s: %w", path, err)
+	}
+	return seed, nil
+}
@@ -0,0 +1,169 @@
+// Package stub serves in-process stand-ins of the user, payment and product
+// services, for running the shop without the rest of the stack.
+package stub
+
+import (
+	"context"
+	paymentpb "github.com/ReStorePUC/protobucket/payment"
+	productpb "github.com/ReStorePUC/protobucket/product"
+	pb "github.com/ReStorePUC/protobucket/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// Server serves the three upstreams on a single local address.
+type Server struct {
+	lis  net.Listener
+	grpc *grpc.Server
+}
+
+// Start serves seed on a random local port.
+func Start(seed *Seed) (*Server, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	users := &userServer{users: map[string]*pb.GetUserResponse{}}
+	for _, user := range seed.Users {
+		users.users[user.Email] = &pb.GetUserResponse{
+			Id:      user.ID,
+			IsAdmin: user.Admin,
+		}
+	}
+
+	products := &productServer{products: map[int]*productpb.GetProductResponse{}}
+	for _, product := range seed.Products {
+		products.products[product.ID] = toProduct(product)
+	}
+
+	payments := &paymentServer{}
+	for _, payment := range seed.Payments {
+		payments.ids = append(payments.ids, payment.ID)
+	}
+
+	s := grpc.NewServer()
+	pb.RegisterUserServer(s, users)
+	productpb.RegisterProductServer(s, products)
+	paymentpb.RegisterPaymentServer(s, payments)
+
+	go s.Serve(lis)
+
+	return &Server{
+		lis:  lis,
+		grpc: s,
+	}, nil
+}
+
+// Address returns the address the upstreams are served on.
+func (s *Server) Address() string {
+	return s.lis.Addr().String()
+}
+
+func (s *Server) Stop() {
+	s.grpc.Stop()
+}
+
+func toProduct(product Product) *productpb.GetProductResponse {
+	available := product.Available == nil || *product.Available
+
+	imgs := []*productpb.Image{}
+	for i, path := range product.Images {
+		imgs = append(imgs, &productpb.Image{
+			Id:        int32(i + 1),
+			ImagePath: path,
+			ProductId: int32(product.ID),
+		})
+	}
+
+	return &productpb.GetProductResponse{
+		Id:          int32(product.ID),
+		Name:        product.Name,
+		Description: product.Description,
+		Categories:  product.Categories,
+		Size:        product.Size,
+		Price:       product.Price,
+		Tax:         product.Tax,
+		Available:   available,
+		StoreId:     int32(product.StoreID),
+		Images:      imgs,
+	}
+}
+
+// userServer serves the seeded users by email.
+type userServer struct {
+	pb.UnimplementedUserServer
+
+	users map[string]*pb.GetUserResponse
+}
+
+func (s *userServer) GetUser(ctx context.Context, in *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	user, ok := s.users[in.Email]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "user %s not found", in.Email)
+	}
+	return user, nil
+}
+
+// productServer serves the seeded products by ID, marking them unavailable
+// once sold.
+type productServer struct {
+	productpb.UnimplementedProductServer
+
+	mu       sync.Mutex
+	products map[int]*productpb.GetProductResponse
+}
+
+func (s *productServer) GetProduct(ctx context.Context, in *productpb.GetProductRequest) (*productpb.GetProductResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, _ := strconv.Atoi(in.Id)
+	product, ok := s.products[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "product %s not found", in.Id)
+	}
+	return proto.Clone(product).(*productpb.GetProductResponse), nil
+}
+
+func (s *productServer) UnavailableProduct(ctx context.Context, in *productpb.UnavailableProductRequest) (*productpb.UnavailableProductResponse, error) {
+	id, err := strconv.Atoi(in.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if product, ok := s.products[id]; ok {
+		product.Available = false
+	}
+	return &productpb.UnavailableProductResponse{}, nil
+}
+
+// paymentServer hands out the seeded payment IDs first, then sequential ones.
+type paymentServer struct {
+	paymentpb.UnimplementedPaymentServer
+
+	mu      sync.Mutex
+	ids     []string
+	created int
+}
+
+func (s *paymentServer) CreatePayment(ctx context.Context, in *paymentpb.CreatePaymentRequest) (*paymentpb.CreatePaymentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.ids) > 0 {
+		id := s.ids[0]
+		s.ids = s.ids[1:]
+		return &paymentpb.CreatePaymentResponse{Id: id}, nil
+	}
+	s.created++
+	return &paymentpb.CreatePaymentResponse{Id: strconv.Itoa(s.created)}, nil
+}